@@ -0,0 +1,472 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// completionShells are the shells for which a completion script can be generated.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// defaultCompletionsPath returns the conventional file name of the given shell's
+// completion script for the command with the given name.
+func defaultCompletionsPath(shell, name string) string {
+	switch shell {
+	case "zsh":
+		return "./_" + name
+	case "fish":
+		return "./" + name + ".fish"
+	default:
+		return "./" + name + ".bash"
+	}
+}
+
+// genCompletions returns a static completion script for the given shell. It completes the
+// options and subcommands of each command, the choices of any options or arguments that
+// have them, and file names for any with a 'clap:complete_files' directive. Options and
+// arguments with a completion function are completed by running the program with the
+// hidden "__complete" argument.
+func genCompletions(shell string, gnuStyle bool, root *command) ([]byte, error) {
+	var b strings.Builder
+	switch shell {
+	case "bash":
+		writeBashCompletions(&b, gnuStyle, root)
+	case "zsh":
+		writeZshCompletions(&b, gnuStyle, root)
+	case "fish":
+		writeFishCompletions(&b, gnuStyle, root)
+	default:
+		return nil, fmt.Errorf("unknown shell '%s' (possible values: %s)", shell, strings.Join(completionShells, ", "))
+	}
+	return []byte(b.String()), nil
+}
+
+// valueCompletion describes what the value of an option or argument can complete to.
+type valueCompletion struct {
+	choices []string
+	files   bool
+	dynamic bool
+}
+
+func newValueCompletion(data *clapData) valueCompletion {
+	if _, ok := data.getConfig("complete_func"); ok {
+		return valueCompletion{dynamic: true}
+	}
+	if choices := data.choices(); choices != nil {
+		return valueCompletion{choices: choices}
+	}
+	_, files := data.getConfig("complete_files")
+	return valueCompletion{files: files}
+}
+
+// posCompletion returns what a positional word of this command can complete to when it's
+// not known which argument (if any) the word is for. The choices are the names of the
+// subcommands along with the choices of the arguments. Since it's likely that the word is
+// meant to be one of those, file names are only offered if there aren't any.
+func (c *command) posCompletion() valueCompletion {
+	var vc valueCompletion
+	for i := range c.Subcmds {
+		vc.choices = append(vc.choices, c.Subcmds[i].allNames()...)
+	}
+	for i := range c.Args {
+		avc := newValueCompletion(&c.Args[i].data)
+		vc.choices = append(vc.choices, avc.choices...)
+		vc.files = vc.files || avc.files
+		vc.dynamic = vc.dynamic || avc.dynamic
+	}
+	vc.files = vc.files && vc.choices == nil && !vc.dynamic
+	return vc
+}
+
+// valueOptWords returns every way that each of this command's options that take a value
+// can be given on the command line as a word on its own (which means the next word is its
+// value). Go style options can be given with either one or two dashes.
+func (c *command) valueOptWords(gnuStyle bool) []string {
+	var words []string
+	opts := c.allOpts()
+	for i := range opts {
+		if opts[i].FieldType.IsBool() {
+			continue
+		}
+		for _, n := range opts[i].runtimeNames(gnuStyle) {
+			words = append(words, n)
+			if !gnuStyle {
+				words = append(words, "-"+n)
+			}
+		}
+	}
+	return words
+}
+
+// runtimeNames returns all of the names of this option as they're given on the command
+// line (e.g. "-v" and "--verbose").
+func (o *option) runtimeNames(gnuStyle bool) []string {
+	var names []string
+	if s := o.runtimeShort(); s != "" {
+		names = append(names, s)
+	}
+	names = append(names, o.runtimeName(gnuStyle))
+	if o.Name == "h" {
+		if gnuStyle {
+			names = append(names, "--help")
+		} else {
+			names = append(names, "-help")
+		}
+	}
+	return names
+}
+
+// allNames returns this command's name followed by any of its aliases.
+func (c *command) allNames() []string {
+	names := []string{c.UsgName()}
+	if csv, ok := c.Data.getConfig("cmd_aliases"); ok {
+		for _, alias := range strings.Split(csv, ",") {
+			names = append(names, strings.TrimSpace(alias))
+		}
+	}
+	return names
+}
+
+// allOpts returns this command's options followed by the global options it inherits.
+func (c *command) allOpts() []option {
+	return append(append([]option(nil), c.Opts...), c.InheritedOpts...)
+}
+
+// path returns the full name of this command (e.g. "mycli subcmd").
+func (c *command) path() string { return c.Parents() + c.UsgName() }
+
+// walk calls fn for this command and every command beneath it.
+func (c *command) walk(fn func(*command)) {
+	fn(c)
+	for i := range c.Subcmds {
+		c.Subcmds[i].walk(fn)
+	}
+}
+
+// shQuote returns the given string in single quotes for a POSIX shell.
+func shQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellFuncName returns a shell function name made from the given command path.
+func shellFuncName(path string) string {
+	return "_" + strings.NewReplacer(" ", "_", "-", "_", ".", "_").Replace(path)
+}
+
+// bashDynamic is the bash code that gets completion candidates from the program itself.
+const bashDynamic = `$("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}")`
+
+func writeBashCompletions(b *strings.Builder, gnuStyle bool, root *command) {
+	name := root.UsgName()
+	fn := shellFuncName(name)
+	fmt.Fprintf(b, "# bash completion for %s; generated by goclap; DO NOT EDIT\n\n", name)
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString("\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}\n")
+	fmt.Fprintf(b, "\tlocal cmd=%s i\n", shQuote(name))
+	if root.HasSubcmds() {
+		b.WriteString("\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+		b.WriteString("\t\tcase \"$cmd ${COMP_WORDS[i]}\" in\n")
+		root.walk(func(c *command) {
+			// The word after an option that takes a value is never a subcommand name.
+			if words := c.valueOptWords(gnuStyle); len(words) > 0 {
+				pats := make([]string, len(words))
+				for i, w := range words {
+					pats[i] = shQuote(c.path() + " " + w)
+				}
+				fmt.Fprintf(b, "\t\t%s) ((i++)) ;;\n", strings.Join(pats, " | "))
+			}
+			for i := range c.Subcmds {
+				sc := &c.Subcmds[i]
+				pats := make([]string, 0, 2)
+				for _, n := range sc.allNames() {
+					pats = append(pats, shQuote(c.path()+" "+n))
+				}
+				fmt.Fprintf(b, "\t\t%s) cmd=%s ;;\n", strings.Join(pats, " | "), shQuote(sc.path()))
+			}
+		})
+		b.WriteString("\t\tesac\n")
+		b.WriteString("\tdone\n")
+	}
+	b.WriteString("\n\tcase $cmd in\n")
+	root.walk(func(c *command) {
+		fmt.Fprintf(b, "\t%s)\n", shQuote(c.path()))
+
+		opts := c.allOpts()
+		var optNames []string
+		var valueCases strings.Builder
+		for i := range opts {
+			o := &opts[i]
+			names := o.runtimeNames(gnuStyle)
+			optNames = append(optNames, names...)
+			if o.FieldType.IsBool() {
+				continue
+			}
+			fmt.Fprintf(&valueCases, "\t\t%s) ", strings.Join(names, " | "))
+			vc := newValueCompletion(&o.data)
+			switch {
+			case vc.dynamic:
+				valueCases.WriteString("COMPREPLY=(" + bashDynamic + "); ")
+			case vc.choices != nil:
+				fmt.Fprintf(&valueCases, "COMPREPLY=($(compgen -W %s -- \"$cur\")); ", shQuote(strings.Join(vc.choices, " ")))
+			case vc.files:
+				valueCases.WriteString("COMPREPLY=($(compgen -f -- \"$cur\")); ")
+			}
+			valueCases.WriteString("return ;;\n")
+		}
+		if valueCases.Len() > 0 {
+			b.WriteString("\t\tcase $prev in\n")
+			b.WriteString(valueCases.String())
+			b.WriteString("\t\tesac\n")
+		}
+		b.WriteString("\t\tif [[ $cur == -* ]]; then\n")
+		fmt.Fprintf(b, "\t\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shQuote(strings.Join(optNames, " ")))
+		b.WriteString("\t\t\treturn\n")
+		b.WriteString("\t\tfi\n")
+
+		vc := c.posCompletion()
+		var replies []string
+		if vc.dynamic {
+			replies = append(replies, bashDynamic)
+		}
+		if len(vc.choices) > 0 {
+			replies = append(replies, fmt.Sprintf("$(compgen -W %s -- \"$cur\")", shQuote(strings.Join(vc.choices, " "))))
+		}
+		if vc.files {
+			replies = append(replies, "$(compgen -f -- \"$cur\")")
+		}
+		if len(replies) > 0 {
+			fmt.Fprintf(b, "\t\tCOMPREPLY=(%s)\n", strings.Join(replies, " "))
+		}
+		b.WriteString("\t\t;;\n")
+	})
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(b, "complete -F %s %s\n", fn, name)
+}
+
+// zshEscape escapes the characters that are special within the descriptions and names
+// given to `_arguments` and `_describe`. The result is meant to go within single quotes.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, ":", `\:`, "'", `'\''`).Replace(s)
+}
+
+func writeZshCompletions(b *strings.Builder, gnuStyle bool, root *command) {
+	name := root.UsgName()
+	fmt.Fprintf(b, "#compdef %s\n", name)
+	b.WriteString("# generated by goclap; DO NOT EDIT\n")
+	dynFn := "_" + shellFuncName(name) + "_complete"
+	if root.HasCompleteFuncSomewhere() {
+		// This function adds the completion candidates that the program itself provides.
+		fmt.Fprintf(b, "\n%s() {\n", dynFn)
+		b.WriteString("\tlocal -a w=(\"${(@Q)${(z)LBUFFER}}\")\n")
+		b.WriteString("\t[[ $LBUFFER == *[[:space:]] ]] && w+=('')\n")
+		b.WriteString("\tcompadd -- ${(f)\"$(\"$w[1]\" __complete \"${(@)w[2,-1]}\")\"}\n")
+		b.WriteString("}\n")
+	}
+	root.walk(func(c *command) {
+		fmt.Fprintf(b, "\n%s() {\n", shellFuncName(c.path()))
+		b.WriteString("\tlocal line state\n")
+		b.WriteString("\t_arguments -C")
+
+		opts := c.allOpts()
+		for i := range opts {
+			o := &opts[i]
+			names := o.runtimeNames(gnuStyle)
+			// Options with more than one name are given as a brace expansion. Unless they
+			// can be repeated (slices), each name excludes the others.
+			var spec string
+			switch {
+			case len(names) == 1 && o.FieldType.IsSlice():
+				spec = "'*'" + names[0]
+			case len(names) == 1:
+				spec = names[0]
+			case o.FieldType.IsSlice():
+				spec = "'*'{" + strings.Join(names, ",") + "}"
+			default:
+				spec = "'(" + strings.Join(names, " ") + ")'{" + strings.Join(names, ",") + "}"
+			}
+			spec += "'[" + zshEscape(plainText(o.data.Blurb)) + "]"
+			if !o.FieldType.IsBool() {
+				spec += ":value:" + zshAction(newValueCompletion(&o.data), dynFn)
+			}
+			spec += "'"
+			fmt.Fprintf(b, " \\\n\t\t%s", spec)
+		}
+		for i := range c.Args {
+			a := &c.Args[i]
+			pos := fmt.Sprint(i + 1)
+			if a.IsVariadic() {
+				pos = "*"
+			}
+			fmt.Fprintf(b, " \\\n\t\t%s", shQuote(pos+":"+a.name+":"+zshAction(newValueCompletion(&a.data), dynFn)))
+		}
+		if c.HasSubcmds() {
+			fmt.Fprintf(b, " \\\n\t\t'%d: :->cmds'", len(c.Args)+1)
+			b.WriteString(" \\\n\t\t'*:: :->args'")
+		}
+		b.WriteString("\n")
+
+		if c.HasSubcmds() {
+			b.WriteString("\tcase $state in\n")
+			b.WriteString("\tcmds)\n")
+			b.WriteString("\t\tlocal -a cmds=(")
+			for i := range c.Subcmds {
+				sc := &c.Subcmds[i]
+				for _, n := range sc.allNames() {
+					fmt.Fprintf(b, "\n\t\t\t'%s:%s'", zshEscape(n), zshEscape(plainText(sc.Data.Blurb)))
+				}
+			}
+			b.WriteString("\n\t\t)\n")
+			b.WriteString("\t\t_describe -t commands 'subcommand' cmds\n")
+			b.WriteString("\t\t;;\n")
+			b.WriteString("\targs)\n")
+			fmt.Fprintf(b, "\t\tcase $line[%d] in\n", len(c.Args)+1)
+			for i := range c.Subcmds {
+				sc := &c.Subcmds[i]
+				pats := make([]string, 0, 2)
+				for _, n := range sc.allNames() {
+					pats = append(pats, shQuote(n))
+				}
+				fmt.Fprintf(b, "\t\t%s) %s ;;\n", strings.Join(pats, " | "), shellFuncName(sc.path()))
+			}
+			b.WriteString("\t\tesac\n")
+			b.WriteString("\t\t;;\n")
+			b.WriteString("\tesac\n")
+		}
+		b.WriteString("}\n")
+	})
+	fmt.Fprintf(b, "\n%s \"$@\"\n", shellFuncName(name))
+}
+
+// zshAction returns the `_arguments` action for completing the given kind of value. The
+// given function is used for values that the program itself completes.
+func zshAction(vc valueCompletion, dynFn string) string {
+	switch {
+	case vc.dynamic:
+		return dynFn
+	case vc.choices != nil:
+		return "(" + strings.Join(vc.choices, " ") + ")"
+	case vc.files:
+		return "_files"
+	}
+	return " "
+}
+
+// fishQuote returns the given string in single quotes for the fish shell.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+func writeFishCompletions(b *strings.Builder, gnuStyle bool, root *command) {
+	name := root.UsgName()
+	cmdFn := "_" + shellFuncName(name) + "_cmd"
+	fmt.Fprintf(b, "# fish completion for %s; generated by goclap; DO NOT EDIT\n\n", name)
+
+	// This function prints the path of the (sub)command being completed.
+	fmt.Fprintf(b, "function %s\n", cmdFn)
+	fmt.Fprintf(b, "\tset -l cmd %s\n", fishQuote(name))
+	if root.HasSubcmds() {
+		b.WriteString("\tset -l words (commandline -opc)\n")
+		b.WriteString("\tset -e words[1]\n")
+		b.WriteString("\tset -l skip\n")
+		b.WriteString("\tfor w in $words\n")
+		// The word after an option that takes a value is never a subcommand name.
+		b.WriteString("\t\tif set -q skip[1]\n")
+		b.WriteString("\t\t\tset skip\n")
+		b.WriteString("\t\t\tcontinue\n")
+		b.WriteString("\t\tend\n")
+		b.WriteString("\t\tswitch \"$cmd $w\"\n")
+		root.walk(func(c *command) {
+			if words := c.valueOptWords(gnuStyle); len(words) > 0 {
+				pats := make([]string, len(words))
+				for i, w := range words {
+					pats[i] = fishQuote(c.path() + " " + w)
+				}
+				fmt.Fprintf(b, "\t\t\tcase %s\n", strings.Join(pats, " "))
+				b.WriteString("\t\t\t\tset skip 1\n")
+			}
+			for i := range c.Subcmds {
+				sc := &c.Subcmds[i]
+				pats := make([]string, 0, 2)
+				for _, n := range sc.allNames() {
+					pats = append(pats, fishQuote(c.path()+" "+n))
+				}
+				fmt.Fprintf(b, "\t\t\tcase %s\n", strings.Join(pats, " "))
+				fmt.Fprintf(b, "\t\t\t\tset cmd %s\n", fishQuote(sc.path()))
+			}
+		})
+		b.WriteString("\t\tend\n")
+		b.WriteString("\tend\n")
+	}
+	b.WriteString("\techo $cmd\n")
+	b.WriteString("end\n\n")
+
+	dynFn := "_" + shellFuncName(name) + "_complete"
+	if root.HasCompleteFuncSomewhere() {
+		// This function prints the completion candidates that the program itself provides.
+		fmt.Fprintf(b, "function %s\n", dynFn)
+		b.WriteString("\tset -l words (commandline -opc)\n")
+		b.WriteString("\tset -l prog $words[1]\n")
+		b.WriteString("\tset -e words[1]\n")
+		b.WriteString("\t$prog __complete $words (commandline -ct)\n")
+		b.WriteString("end\n\n")
+	}
+
+	fmt.Fprintf(b, "complete -c %s -f\n", name)
+	root.walk(func(c *command) {
+		cond := fishQuote(fmt.Sprintf("test (%s) = \"%s\"", cmdFn, c.path()))
+		prefix := fmt.Sprintf("complete -c %s -n %s", name, cond)
+		b.WriteString("\n")
+
+		opts := c.allOpts()
+		for i := range opts {
+			o := &opts[i]
+			var line strings.Builder
+			line.WriteString(prefix)
+			for _, n := range o.runtimeNames(gnuStyle) {
+				switch {
+				case strings.HasPrefix(n, "--"):
+					line.WriteString(" -l " + n[2:])
+				case len(n) == 2:
+					line.WriteString(" -s " + n[1:])
+				default:
+					line.WriteString(" -o " + n[1:])
+				}
+			}
+			if !o.FieldType.IsBool() {
+				vc := newValueCompletion(&o.data)
+				switch {
+				case vc.dynamic:
+					line.WriteString(" -x -a " + fishQuote("("+dynFn+")"))
+				case vc.choices != nil:
+					line.WriteString(" -x -a " + fishQuote(strings.Join(vc.choices, " ")))
+				case vc.files:
+					line.WriteString(" -r -F")
+				default:
+					line.WriteString(" -x")
+				}
+			}
+			line.WriteString(" -d " + fishQuote(plainText(o.data.Blurb)))
+			b.WriteString(line.String() + "\n")
+		}
+		for i := range c.Subcmds {
+			sc := &c.Subcmds[i]
+			for _, n := range sc.allNames() {
+				fmt.Fprintf(b, "%s -a %s -d %s\n", prefix, fishQuote(n), fishQuote(plainText(sc.Data.Blurb)))
+			}
+		}
+		for i := range c.Args {
+			a := &c.Args[i]
+			vc := newValueCompletion(&a.data)
+			switch {
+			case vc.dynamic:
+				fmt.Fprintf(b, "%s -a %s\n", prefix, fishQuote("("+dynFn+")"))
+			case vc.choices != nil:
+				fmt.Fprintf(b, "%s -a %s -d %s\n", prefix, fishQuote(strings.Join(vc.choices, " ")), fishQuote(plainText(a.data.Blurb)))
+			}
+		}
+		if c.posCompletion().files {
+			fmt.Fprintf(b, "%s -F\n", prefix)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffOp is a single line of a diff: either unchanged (' '), deleted ('-') or inserted
+// ('+').
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// unifiedDiff returns a unified diff (with three lines of context) that turns the existing
+// contents of the named file into the generated contents.
+func unifiedDiff(name string, existing, generated []byte) string {
+	ops := diffLines(splitLines(string(existing)), splitLines(string(generated)))
+
+	// The line numbers (zero-based) of each op within the existing and generated content.
+	aIdx := make([]int, len(ops)+1)
+	bIdx := make([]int, len(ops)+1)
+	for i, op := range ops {
+		aIdx[i+1], bIdx[i+1] = aIdx[i], bIdx[i]
+		if op.kind != '+' {
+			aIdx[i+1]++
+		}
+		if op.kind != '-' {
+			bIdx[i+1]++
+		}
+	}
+
+	const numCtx = 3
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s (generated)\n", name, name)
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		// A hunk continues until there are more unchanged lines in a row than would be needed
+		// for the context of both it and the next one.
+		end := i + 1
+		for j := i; j < len(ops) && j-end <= 2*numCtx; j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			}
+		}
+		start := max(i-numCtx, 0)
+		stop := min(end+numCtx, len(ops))
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(aIdx[start], aIdx[stop]-aIdx[start]),
+			hunkRange(bIdx[start], bIdx[stop]-bIdx[start]))
+		for _, op := range ops[start:stop] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+		i = stop
+	}
+	return b.String()
+}
+
+// hunkRange returns the "start,count" range of a hunk header given the zero-based start
+// line and the number of lines.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the ops that turn a into b based on their longest common subsequence.
+// Any common prefix and suffix are trimmed first since most changes are small.
+func diffLines(a, b []string) []diffOp {
+	var pre int
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	var suf int
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, l := range a[:pre] {
+		ops = append(ops, diffOp{' ', l})
+	}
+
+	am, bm := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	// lcs[i][j] is the length of the longest common subsequence of am[i:] and bm[j:].
+	lcs := make([][]int32, len(am)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(bm)+1)
+	}
+	for i := len(am) - 1; i >= 0; i-- {
+		for j := len(bm) - 1; j >= 0; j-- {
+			if am[i] == bm[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(am) || j < len(bm) {
+		switch {
+		case i < len(am) && j < len(bm) && am[i] == bm[j]:
+			ops = append(ops, diffOp{' ', am[i]})
+			i++
+			j++
+		case i < len(am) && (j == len(bm) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', am[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', bm[j]})
+			j++
+		}
+	}
+
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, diffOp{' ', l})
+	}
+	return ops
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numberedLines returns n lines ("l1" through "ln") with any of the given replacements.
+func numberedLines(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if s, ok := replace[i]; ok {
+			b.WriteString(s + "\n")
+			continue
+		}
+		fmt.Fprintf(&b, "l%d\n", i)
+	}
+	return b.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		existing string
+		gen      string
+		want     string
+	}{
+		{
+			name:     "single change",
+			existing: numberedLines(10, nil),
+			gen:      numberedLines(10, map[int]string{5: "x"}),
+			want: `@@ -2,7 +2,7 @@
+ l2
+ l3
+ l4
+-l5
++x
+ l6
+ l7
+ l8
+`,
+		},
+		{
+			// Six unchanged lines are exactly enough for the context of both changes.
+			name:     "adjacent changes share a hunk",
+			existing: numberedLines(20, nil),
+			gen:      numberedLines(20, map[int]string{5: "x", 12: "y"}),
+			want: `@@ -2,14 +2,14 @@
+ l2
+ l3
+ l4
+-l5
++x
+ l6
+ l7
+ l8
+ l9
+ l10
+ l11
+-l12
++y
+ l13
+ l14
+ l15
+`,
+		},
+		{
+			name:     "distant changes get separate hunks",
+			existing: numberedLines(20, nil),
+			gen:      numberedLines(20, map[int]string{5: "x", 13: "y"}),
+			want: `@@ -2,7 +2,7 @@
+ l2
+ l3
+ l4
+-l5
++x
+ l6
+ l7
+ l8
+@@ -10,7 +10,7 @@
+ l10
+ l11
+ l12
+-l13
++y
+ l14
+ l15
+ l16
+`,
+		},
+		{
+			name:     "lines added and removed at the edges",
+			existing: "a\nb\nc\nd\n",
+			gen:      "b\nc\nd\ne\n",
+			want: `@@ -1,4 +1,4 @@
+-a
+ b
+ c
+ d
++e
+`,
+		},
+		{
+			name:     "new file",
+			existing: "",
+			gen:      "a\nb\n",
+			want: `@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name:     "emptied file",
+			existing: "a\nb\n",
+			gen:      "",
+			want: `@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			want := "--- f.go\n+++ f.go (generated)\n" + tc.want
+			if got := unifiedDiff("f.go", []byte(tc.existing), []byte(tc.gen)); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestDiffLinesPutsDeletionsFirst(t *testing.T) {
+	ops := diffLines([]string{"a", "old", "z"}, []string{"a", "new", "z"})
+	var got []string
+	for _, op := range ops {
+		got = append(got, string(op.kind)+op.line)
+	}
+	want := []string{" a", "-old", "+new", " z"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// directivePlace is a set of the places where a 'clap:' directive can be used.
+type directivePlace uint8
+
+const (
+	onCommand directivePlace = 1 << iota
+	onOption
+	onArgument
+
+	onField = onOption | onArgument
+)
+
+func (p directivePlace) String() string {
+	switch p {
+	case onCommand:
+		return "command types"
+	case onOption:
+		return "options"
+	case onArgument:
+		return "arguments"
+	case onField:
+		return "options and arguments"
+	}
+	return fmt.Sprintf("directivePlace(%d)", uint8(p))
+}
+
+// directiveValue is the shape of the value that a directive expects.
+type directiveValue uint8
+
+const (
+	valueNone directiveValue = iota // The directive takes no value.
+	valueWord                       // A single word without any spaces.
+	valueList                       // A comma separated list of non-empty items.
+	valueText                       // Any non-empty text.
+)
+
+func (v directiveValue) check(val string) error {
+	switch {
+	case v == valueNone && val != "":
+		return errors.New("takes no value")
+	case v != valueNone && val == "":
+		return errors.New("is missing its value")
+	case v == valueWord && strings.ContainsAny(val, " \t"):
+		return fmt.Errorf("value '%s' must be a single word", val)
+	case v == valueList:
+		for _, item := range strings.Split(val, ",") {
+			if strings.TrimSpace(item) == "" {
+				return fmt.Errorf("value '%s' has an empty item", val)
+			}
+		}
+	}
+	return nil
+}
+
+type directiveSpec struct {
+	place      directivePlace
+	value      directiveValue
+	repeatable bool
+}
+
+// directives holds every known 'clap:' directive by its key.
+var directives = map[string]directiveSpec{
+	"cmd_name":             {place: onCommand, value: valueWord},
+	"cmd_aliases":          {place: onCommand, value: valueList},
+	"cmd_usage":            {place: onCommand, value: valueText, repeatable: true},
+	"cmd_allow_extra_args": {place: onCommand, value: valueNone},
+
+	"opt":             {place: onOption, value: valueList},
+	"opt_required":    {place: onOption, value: valueNone},
+	"opt_sep":         {place: onOption, value: valueText},
+	"opt_time_layout": {place: onOption, value: valueText},
+	"opt_arg_name":    {place: onOption, value: valueWord},
+	"opt_global":      {place: onOption, value: valueNone},
+
+	"arg_required":    {place: onArgument, value: valueNone},
+	"arg_name":        {place: onArgument, value: valueWord},
+	"arg_time_layout": {place: onArgument, value: valueText},
+	"extra_args":      {place: onArgument, value: valueNone},
+
+	"env":            {place: onField, value: valueWord},
+	"default":        {place: onField, value: valueText},
+	"choices":        {place: onField, value: valueList},
+	"min":            {place: onField, value: valueWord},
+	"max":            {place: onField, value: valueWord},
+	"complete_func":  {place: onField, value: valueWord},
+	"complete_files": {place: onField, value: valueNone},
+}
+
+// checkDirectives returns an error if any of the given directives is unknown, can't be
+// used in the given place, has the wrong shape of value, or is repeated when it can't be.
+func checkDirectives(data *clapData, place directivePlace) error {
+	seen := make(map[string]bool, len(data.configs))
+	for _, cfg := range data.configs {
+		spec, ok := directives[cfg.key]
+		if !ok {
+			if s := suggestDirective(cfg.key); s != "" {
+				return fmt.Errorf("unknown directive 'clap:%s' (did you mean 'clap:%s'?)", cfg.key, s)
+			}
+			return fmt.Errorf("unknown directive 'clap:%s'", cfg.key)
+		}
+		if spec.place&place == 0 {
+			return fmt.Errorf("'clap:%s' is only valid on %s", cfg.key, spec.place)
+		}
+		if seen[cfg.key] && !spec.repeatable {
+			return fmt.Errorf("duplicate 'clap:%s' directive", cfg.key)
+		}
+		seen[cfg.key] = true
+		if err := spec.value.check(cfg.val); err != nil {
+			return fmt.Errorf("'clap:%s' %w", cfg.key, err)
+		}
+	}
+	return nil
+}
+
+// suggestDirective returns the known directive closest to the given unknown one, or an
+// empty string if none of them are close enough to be a likely typo.
+func suggestDirective(key string) string {
+	keys := make([]string, 0, len(directives))
+	for k := range directives {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	best, bestDist := "", max(len(key)/3, 2)+1
+	for _, k := range keys {
+		if d := editDistance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between the two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
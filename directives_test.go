@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckDirectives(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		place   directivePlace
+		configs []clapConfig
+		wantErr string
+	}{
+		{
+			name:  "valid option",
+			place: onField,
+			configs: []clapConfig{
+				{"opt", "verbose,v"},
+				{"env", "VERBOSE"},
+				{"default", "true"},
+			},
+		},
+		{
+			name:  "repeatable",
+			place: onCommand,
+			configs: []clapConfig{
+				{"cmd_usage", "[options] <file>"},
+				{"cmd_usage", "-list"},
+			},
+		},
+		{
+			name:    "unknown with suggestion",
+			place:   onField,
+			configs: []clapConfig{{"opt_requried", ""}},
+			wantErr: "unknown directive 'clap:opt_requried' (did you mean 'clap:opt_required'?)",
+		},
+		{
+			name:    "unknown without suggestion",
+			place:   onField,
+			configs: []clapConfig{{"something_else", ""}},
+			wantErr: "unknown directive 'clap:something_else'",
+		},
+		{
+			name:    "wrong place",
+			place:   onField,
+			configs: []clapConfig{{"cmd_aliases", "ls"}},
+			wantErr: "'clap:cmd_aliases' is only valid on command types",
+		},
+		{
+			name:    "duplicate",
+			place:   onField,
+			configs: []clapConfig{{"env", "A"}, {"env", "B"}},
+			wantErr: "duplicate 'clap:env' directive",
+		},
+		{
+			name:    "unexpected value",
+			place:   onField,
+			configs: []clapConfig{{"opt_required", "yes"}},
+			wantErr: "'clap:opt_required' takes no value",
+		},
+		{
+			name:    "missing value",
+			place:   onField,
+			configs: []clapConfig{{"arg_name", ""}},
+			wantErr: "'clap:arg_name' is missing its value",
+		},
+		{
+			name:    "more than one word",
+			place:   onField,
+			configs: []clapConfig{{"env", "MY VAR"}},
+			wantErr: "'clap:env' value 'MY VAR' must be a single word",
+		},
+		{
+			name:    "empty list item",
+			place:   onField,
+			configs: []clapConfig{{"choices", "a,,b"}},
+			wantErr: "'clap:choices' value 'a,,b' has an empty item",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			checkErr(t, checkDirectives(&clapData{configs: tc.configs}, tc.place), tc.wantErr)
+		})
+	}
+}
+
+func TestSuggestDirective(t *testing.T) {
+	for key, want := range map[string]string{
+		"opt_requried":   "opt_required",
+		"choises":        "choices",
+		"arg_nmae":       "arg_name",
+		"cmd_alias":      "cmd_aliases",
+		"maxx":           "max",
+		"completion":     "",
+		"something_else": "",
+	} {
+		if got := suggestDirective(key); got != want {
+			t.Errorf("suggestDirective(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// TestDirectivesAreDocumented makes sure that the README covers every known directive.
+func TestDirectivesAreDocumented(t *testing.T) {
+	readme, err := os.ReadFile("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key := range directives {
+		if !strings.Contains(string(readme), "`clap:"+key) {
+			t.Errorf("the README doesn't document 'clap:%s'", key)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"go/token"
+)
+
+// The following types mirror the command model for its JSON representation.
+
+type jsonCommand struct {
+	Name           string         `json:"name"`
+	Aliases        []string       `json:"aliases,omitempty"`
+	TypeName       string         `json:"typeName"`
+	PkgPath        string         `json:"pkgPath,omitempty"`
+	FieldName      string         `json:"fieldName"`
+	Pos            *jsonPos       `json:"pos,omitempty"`
+	Data           jsonData       `json:"data"`
+	Opts           []jsonOption   `json:"options"`
+	Args           []jsonArgument `json:"arguments"`
+	Subcmds        []jsonCommand  `json:"subcommands"`
+	ExtraArgsField string         `json:"extraArgsField,omitempty"`
+}
+
+type jsonOption struct {
+	Name      string   `json:"name"`
+	Short     string   `json:"short,omitempty"`
+	FieldName string   `json:"fieldName,omitempty"`
+	Type      string   `json:"type"`
+	Pos       *jsonPos `json:"pos,omitempty"`
+	Data      jsonData `json:"data"`
+}
+
+type jsonArgument struct {
+	Name      string   `json:"name"`
+	FieldName string   `json:"fieldName"`
+	Type      string   `json:"type"`
+	Pos       *jsonPos `json:"pos,omitempty"`
+	Data      jsonData `json:"data"`
+}
+
+type jsonData struct {
+	Blurb    string       `json:"blurb"`
+	Overview []string     `json:"overview"`
+	Configs  []jsonConfig `json:"configs"`
+}
+
+type jsonConfig struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type jsonPos struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// dumpJSON returns the indented JSON representation of the given command tree.
+func dumpJSON(root *command) ([]byte, error) {
+	b, err := json.MarshalIndent(newJSONCommand(root), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func newJSONCommand(c *command) jsonCommand {
+	jc := jsonCommand{
+		Name:           c.UsgName(),
+		Aliases:        c.allNames()[1:],
+		TypeName:       c.TypeName,
+		PkgPath:        c.pkgPath,
+		FieldName:      c.FieldName,
+		Pos:            newJSONPos(c.pos),
+		Data:           newJSONData(&c.Data),
+		Opts:           make([]jsonOption, len(c.Opts)),
+		Args:           make([]jsonArgument, len(c.Args)),
+		Subcmds:        make([]jsonCommand, len(c.Subcmds)),
+		ExtraArgsField: c.ExtraArgsField,
+	}
+	for i := range c.Opts {
+		o := &c.Opts[i]
+		jc.Opts[i] = jsonOption{
+			Name:      o.Name,
+			Short:     o.Short,
+			FieldName: o.FieldName,
+			Type:      string(o.FieldType),
+			Pos:       newJSONPos(o.pos),
+			Data:      newJSONData(&o.data),
+		}
+	}
+	for i := range c.Args {
+		a := &c.Args[i]
+		jc.Args[i] = jsonArgument{
+			Name:      a.name,
+			FieldName: a.FieldName,
+			Type:      string(a.FieldType),
+			Pos:       newJSONPos(a.pos),
+			Data:      newJSONData(&a.data),
+		}
+	}
+	for i := range c.Subcmds {
+		jc.Subcmds[i] = newJSONCommand(&c.Subcmds[i])
+	}
+	return jc
+}
+
+func newJSONData(d *clapData) jsonData {
+	jd := jsonData{
+		Blurb:    plainText(d.Blurb),
+		Overview: make([]string, len(d.overview)),
+		Configs:  make([]jsonConfig, len(d.configs)),
+	}
+	for i := range d.overview {
+		jd.Overview[i] = plainText(d.overview[i])
+	}
+	for i := range d.configs {
+		jd.Configs[i] = jsonConfig{Key: d.configs[i].key, Value: d.configs[i].val}
+	}
+	return jd
+}
+
+// newJSONPos returns nil for invalid positions (such as that of the help option).
+func newJSONPos(p token.Position) *jsonPos {
+	if !p.IsValid() {
+		return nil
+	}
+	return &jsonPos{File: p.Filename, Line: p.Line, Column: p.Column}
+}
@@ -0,0 +1,41 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+)
+
+// Roll some dice.
+type mycli struct {
+	// How many sides each die has.
+	//
+	// clap:opt sides
+	// clap:min 2
+	// clap:max 100
+	// clap:default 6
+	sides int
+	// Add this to the total.
+	//
+	// clap:opt plus
+	// clap:min 0
+	plus int8
+	// How many dice to roll.
+	//
+	// clap:arg_required
+	// clap:min 1
+	count uint
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	total := int(c.plus)
+	for range c.count {
+		total += 1 + rand.IntN(c.sides)
+	}
+	fmt.Println(total)
+}
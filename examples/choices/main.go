@@ -0,0 +1,43 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Print some text in a given case.
+type mycli struct {
+	// How to change the case of the text.
+	//
+	// clap:opt case
+	// clap:choices upper,lower,title
+	// clap:default "lower"
+	textCase string
+	// The words to print.
+	//
+	// clap:arg_required
+	words []string
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	s := strings.Join(c.words, " ")
+	switch c.textCase {
+	case "upper":
+		s = strings.ToUpper(s)
+	case "lower":
+		s = strings.ToLower(s)
+	case "title":
+		words := strings.Fields(strings.ToLower(s))
+		for i, w := range words {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+		s = strings.Join(words, " ")
+	}
+	fmt.Println(s)
+}
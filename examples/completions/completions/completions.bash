@@ -0,0 +1,23 @@
+# bash completion for completions; generated by goclap; DO NOT EDIT
+
+_completions() {
+	local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}
+	local cmd='completions' i
+
+	case $cmd in
+	'completions')
+		case $prev in
+		-to) COMPREPLY=($(compgen -W 'json yaml toml' -- "$cur")); return ;;
+		-profile) COMPREPLY=($("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}")); return ;;
+		-out) COMPREPLY=($(compgen -f -- "$cur")); return ;;
+		esac
+		if [[ $cur == -* ]]; then
+			COMPREPLY=($(compgen -W '-to -profile -out -h -help' -- "$cur"))
+			return
+		fi
+		COMPREPLY=($(compgen -f -- "$cur"))
+		;;
+	esac
+}
+
+complete -F _completions completions
@@ -0,0 +1,21 @@
+# fish completion for completions; generated by goclap; DO NOT EDIT
+
+function __completions_cmd
+	set -l cmd 'completions'
+	echo $cmd
+end
+
+function __completions_complete
+	set -l words (commandline -opc)
+	set -l prog $words[1]
+	set -e words[1]
+	$prog __complete $words (commandline -ct)
+end
+
+complete -c completions -f
+
+complete -c completions -n 'test (__completions_cmd) = "completions"' -o to -x -a 'json yaml toml' -d 'The format to convert to'
+complete -c completions -n 'test (__completions_cmd) = "completions"' -o profile -x -a '(__completions_complete)' -d 'The profile to convert with'
+complete -c completions -n 'test (__completions_cmd) = "completions"' -o out -r -F -d 'Write to this file instead of stdout'
+complete -c completions -n 'test (__completions_cmd) = "completions"' -s h -o help -d 'Show this help message'
+complete -c completions -n 'test (__completions_cmd) = "completions"' -F
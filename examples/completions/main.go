@@ -0,0 +1,53 @@
+package main
+
+//go:generate goclap -type mycli
+//go:generate goclap -type mycli -completions bash -out completions/completions.bash
+//go:generate goclap -type mycli -completions zsh -out completions/_completions
+//go:generate goclap -type mycli -completions fish -out completions/completions.fish
+
+import (
+	"fmt"
+	"os"
+)
+
+// Convert a file from one format to another.
+type mycli struct {
+	// The format to convert to.
+	//
+	// clap:opt to
+	// clap:choices json,yaml,toml
+	to string
+	// The profile to convert with.
+	//
+	// clap:opt profile
+	// clap:complete_func listProfiles
+	profile string
+	// Write to this file instead of stdout.
+	//
+	// clap:opt out
+	// clap:complete_files
+	out string
+	// The file to convert.
+	//
+	// clap:arg_required
+	// clap:complete_files
+	input string
+}
+
+// listProfiles returns the names of the available conversion profiles. They could just as
+// well come from a config file. Only the ones that start with the prefix being completed
+// are offered, so filtering them here is optional.
+func listProfiles(prefix string) []string {
+	return []string{"compact", "pretty", "strict"}
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	dst := c.out
+	if dst == "" {
+		dst = "stdout"
+	}
+	fmt.Printf("converting %s to %s (%s) and writing it to %s\n", c.input, c.to, c.profile, dst)
+}
@@ -0,0 +1,61 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// logLevel is a custom option type that implements `flag.Value`.
+type logLevel int
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l *logLevel) String() string { return levelNames[*l] }
+
+func (l *logLevel) Set(s string) error {
+	for i, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			*l = logLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of: %s", strings.Join(levelNames, ", "))
+}
+
+// Print where a server would listen.
+type mycli struct {
+	// How much to log (debug, info, warn or error).
+	//
+	// clap:opt level
+	level logLevel
+	// Also allow connections from these addresses (can be repeated).
+	//
+	// clap:opt allow
+	allowed []netip.Addr
+	// Forward requests to this URL instead of handling them.
+	//
+	// clap:opt forward
+	forward *url.URL
+	// The address to listen on.
+	//
+	// clap:arg_required
+	addr netip.AddrPort
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	fmt.Printf("listening on %s (IPv6: %t) at level %s\n", c.addr, c.addr.Addr().Is6(), &c.level)
+	for _, a := range c.allowed {
+		fmt.Println("allowing", a)
+	}
+	if c.forward != nil {
+		fmt.Printf("forwarding to %s (host %s)\n", c.forward, c.forward.Host)
+	}
+}
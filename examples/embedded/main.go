@@ -0,0 +1,62 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+)
+
+// Get or put values on a key-value server.
+type mycli struct {
+	get *get
+	put *put
+}
+
+// serverOpts are the options that every subcommand has in common.
+type serverOpts struct {
+	// The server to connect to.
+	//
+	// clap:opt server
+	// clap:default "localhost:7000"
+	server string
+	// Give up after this many seconds.
+	//
+	// clap:opt timeout
+	// clap:default 5
+	timeout uint
+}
+
+// Get the value of a key.
+type get struct {
+	serverOpts
+	// The key to look up.
+	//
+	// clap:arg_required
+	key string
+}
+
+// Set the value of a key.
+type put struct {
+	serverOpts
+	// The key to set.
+	//
+	// clap:arg_required
+	key string
+	// The key's new value.
+	//
+	// clap:arg_required
+	value string
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	switch {
+	case c.get != nil:
+		fmt.Printf("GET %s from %s (timeout %ds)\n", c.get.key, c.get.server, c.get.timeout)
+	case c.put != nil:
+		fmt.Printf("PUT %s=%s to %s (timeout %ds)\n", c.put.key, c.put.value, c.put.server, c.put.timeout)
+	}
+}
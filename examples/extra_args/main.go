@@ -0,0 +1,35 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Print the command line that would run a program in a given directory.
+type mycli struct {
+	// The directory to run the program in.
+	//
+	// clap:opt dir
+	dir string
+	// The program to run.
+	//
+	// clap:arg_required
+	prog string
+	// The program's arguments.
+	//
+	// clap:extra_args
+	progArgs []string
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	if c.dir != "" {
+		fmt.Printf("cd %s && ", c.dir)
+	}
+	fmt.Println(strings.Join(append([]string{c.prog}, c.progArgs...), " "))
+}
@@ -0,0 +1,58 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+)
+
+// Send or list messages on a server.
+type mycli struct {
+	// The token to authenticate with.
+	//
+	// clap:opt token
+	// clap:opt_global
+	// clap:opt_required
+	// clap:env MY_TOKEN
+	token string
+	// Print extra details.
+	//
+	// clap:opt verbose
+	// clap:opt_global
+	verbose bool
+
+	send *send
+	list *list
+}
+
+// Send a message.
+type send struct {
+	// The message to send.
+	//
+	// clap:arg_required
+	msg string
+}
+
+// List the messages sent so far.
+type list struct {
+	// Only list this many messages.
+	//
+	// clap:opt n
+	limit uint
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	if c.verbose {
+		fmt.Printf("authenticating with a %d character token\n", len(c.token))
+	}
+	switch {
+	case c.send != nil:
+		fmt.Println("sent:", c.send.msg)
+	case c.list != nil:
+		fmt.Printf("no messages (limit %d)\n", c.list.limit)
+	}
+}
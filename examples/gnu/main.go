@@ -0,0 +1,68 @@
+package main
+
+//go:generate goclap -type mycli -style gnu
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Print the lines of the given text that contain a pattern.
+type mycli struct {
+	// Ignore case distinctions.
+	//
+	// clap:opt ignore-case,i
+	ignoreCase bool
+	// Print the line number before each line.
+	//
+	// clap:opt line-number,n
+	lineNumber bool
+	// Stop after this many matching lines.
+	//
+	// clap:opt max-count,m
+	// clap:opt_arg_name num
+	maxCount uint
+	// Only print a count of the matching lines.
+	//
+	// clap:opt count
+	count bool
+	// The pattern to look for.
+	//
+	// clap:arg_required
+	pattern string
+	// The text to search.
+	//
+	// clap:arg_required
+	text string
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	pattern, text := c.pattern, c.text
+	if c.ignoreCase {
+		pattern, text = strings.ToLower(pattern), strings.ToLower(text)
+	}
+	orig := strings.Split(c.text, "\n")
+	var matches uint
+	for i, line := range strings.Split(text, "\n") {
+		if !strings.Contains(line, pattern) {
+			continue
+		}
+		matches++
+		if !c.count {
+			if c.lineNumber {
+				fmt.Printf("%d:", i+1)
+			}
+			fmt.Println(orig[i])
+		}
+		if matches == c.maxCount {
+			break
+		}
+	}
+	if c.count {
+		fmt.Println(matches)
+	}
+}
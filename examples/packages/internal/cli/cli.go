@@ -0,0 +1,32 @@
+// Package cli holds the option group and the subcommands of the packages example. Only
+// their exported fields are used because the generated code is in another package.
+package cli
+
+// StoreOpts are the options for where notes are kept.
+type StoreOpts struct {
+	// The directory that holds the notes.
+	//
+	// clap:opt dir
+	// clap:default "./notes"
+	Dir string
+}
+
+// Add a note.
+type Add struct {
+	// Tag the note (can be repeated).
+	//
+	// clap:opt tag
+	Tags []string
+	// The note's text.
+	//
+	// clap:arg_required
+	Text string
+}
+
+// List the notes.
+type List struct {
+	// Only list notes with this tag.
+	//
+	// clap:opt tag
+	Tag string
+}
@@ -0,0 +1,29 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/steverusso/goclap/examples/packages/internal/cli"
+)
+
+// Keep notes in a directory.
+type mycli struct {
+	cli.StoreOpts
+	add  *cli.Add
+	list *cli.List
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	switch {
+	case c.add != nil:
+		fmt.Printf("adding %q to %s with tags %q\n", c.add.Text, c.Dir, c.add.Tags)
+	case c.list != nil:
+		fmt.Printf("listing notes in %s tagged %q\n", c.Dir, c.list.Tag)
+	}
+}
@@ -3,18 +3,18 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 )
 
 type clapCommand struct {
-	usage func() string
-	opts  []clapInput
-	args  []clapInput
+	opts []clapInput
+	args []clapInput
 }
 
 type clapInput struct {
@@ -24,7 +24,7 @@ type clapInput struct {
 	required bool
 }
 
-func (in *clapInput) parseEnv() error {
+func (in *clapInput) parseEnv() *ClapError {
 	if in.envName == "" {
 		return nil
 	}
@@ -33,37 +33,152 @@ func (in *clapInput) parseEnv() error {
 		return nil
 	}
 	if err := in.value.Set(s); err != nil {
-		return fmt.Errorf("parsing env var '%s': %w", in.envName, err)
+		return &ClapError{Kind: ClapKindInvalidEnvVar, Name: in.envName, Value: s, Err: err}
 	}
 	return nil
 }
 
-func clapFatalf(cmdName, format string, args ...any) {
-	msg := fmt.Sprintf(format, args...)
-	fmt.Fprintf(os.Stderr, "error: %s.\nRun '%s -h' for usage.\n", msg, cmdName)
+// ErrClapHelp is the underlying error of a [ClapError] when the help option is provided.
+var ErrClapHelp = errors.New("help requested")
+
+// ClapErrorKind identifies the kind of problem a [ClapError] describes.
+type ClapErrorKind int
+
+const (
+	ClapKindHelp               ClapErrorKind = iota // The help option was provided.
+	ClapKindUnknownOption                           // An undefined option was provided.
+	ClapKindMissingOptionValue                      // A non-boolean option was given no value.
+	ClapKindInvalidOptionValue                      // An option's value could not be parsed.
+	ClapKindMissingOption                           // A required option was not provided.
+	ClapKindInvalidEnvVar                           // An env var's value could not be parsed.
+	ClapKindMissingArg                              // A required argument was not provided.
+	ClapKindInvalidArgValue                         // An argument's value could not be parsed.
+	ClapKindUnexpectedArg                           // An extra argument was provided.
+	ClapKindMissingSubcmd                           // No subcommand was provided.
+	ClapKindUnknownSubcmd                           // An undefined subcommand was provided.
+)
+
+// ClapError is the error returned when command line arguments can't be parsed.
+type ClapError struct {
+	Kind ClapErrorKind
+	// CmdPath is the full name of the command that failed (e.g. "mycli subcmd").
+	CmdPath string
+	// Name is that of the offending option, argument, env var or subcommand (if any).
+	Name string
+	// Value is the offending value (if any).
+	Value string
+	// Err is the underlying cause (if any).
+	Err error
+
+	usage func() string
+}
+
+func (e *ClapError) Error() string {
+	switch e.Kind {
+	case ClapKindHelp:
+		return ErrClapHelp.Error()
+	case ClapKindUnknownOption:
+		return fmt.Sprintf("unknown option '%s'", e.Name)
+	case ClapKindMissingOptionValue:
+		return fmt.Sprintf("option '%s' needs a value", e.Name)
+	case ClapKindInvalidOptionValue:
+		return fmt.Sprintf("invalid value '%s' for option '%s': %v", e.Value, e.Name, e.Err)
+	case ClapKindMissingOption:
+		return fmt.Sprintf("missing required option '%s'", e.Name)
+	case ClapKindInvalidEnvVar:
+		return fmt.Sprintf("invalid value '%s' for env var '%s': %v", e.Value, e.Name, e.Err)
+	case ClapKindMissingArg:
+		return fmt.Sprintf("missing required arg '%s'", e.Name)
+	case ClapKindInvalidArgValue:
+		return fmt.Sprintf("invalid value '%s' for argument '%s': %v", e.Value, e.Name, e.Err)
+	case ClapKindUnexpectedArg:
+		return fmt.Sprintf("unexpected argument '%s'", e.Value)
+	case ClapKindMissingSubcmd:
+		return "no subcommand provided"
+	case ClapKindUnknownSubcmd:
+		return fmt.Sprintf("unknown subcommand '%s'", e.Value)
+	}
+	return fmt.Sprintf("parsing arguments: %v", e.Err)
+}
+
+func (e *ClapError) Unwrap() error { return e.Err }
+
+// clapExit prints the usage message and exits successfully if the given error is due to
+// the help option being provided. Otherwise, it prints the error and exits unsuccessfully.
+func clapExit(err error) {
+	var ce *ClapError
+	if !errors.As(err, &ce) {
+		fmt.Fprintf(os.Stderr, "error: %v.\n", err)
+		os.Exit(2)
+	}
+	if ce.Kind == ClapKindHelp {
+		fmt.Println(ce.usage())
+		os.Exit(0)
+	}
+	fmt.Fprintf(os.Stderr, "error: %v.\nRun '%s -h' for usage.\n", ce, ce.CmdPath)
 	os.Exit(2)
 }
 
-func (cc *clapCommand) parse(args []string) ([]string, error) {
-	f := flag.FlagSet{Usage: func() {}}
-	f.SetOutput(io.Discard)
+func (cc *clapCommand) parse(args []string) ([]string, *ClapError) {
 	for i := range cc.opts {
-		o := &cc.opts[i]
-		if err := o.parseEnv(); err != nil {
+		if err := cc.opts[i].parseEnv(); err != nil {
 			return nil, err
 		}
-		f.Var(o.value, o.name, "")
 	}
 
-	if err := f.Parse(args); err != nil {
-		if err == flag.ErrHelp {
-			fmt.Println(cc.usage())
-			os.Exit(0)
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			// Option parsing stops just before the first non-option argument.
+			rest = append(rest, args[i:]...)
+			break
 		}
-		return nil, err
-	}
 
-	rest := f.Args()
+		// Gather each option (there can be multiple bundled short ones) and its value.
+		type optAndVal struct {
+			o       *clapInput
+			name    string
+			val     string
+			needVal bool
+		}
+		var ovs []optAndVal
+		// Like the standard library's flag package, either one or two dashes can be used.
+		name, val, hasVal := strings.Cut(arg, "=")
+		if strings.HasPrefix(name, "--") {
+			name = name[1:]
+		}
+		if name == "-h" || name == "-help" {
+			return nil, &ClapError{Kind: ClapKindHelp, Err: ErrClapHelp}
+		}
+		o := cc.findOpt(name)
+		if o == nil {
+			return nil, &ClapError{Kind: ClapKindUnknownOption, Name: name}
+		}
+		ov := optAndVal{o: o, name: name, val: val}
+		if !hasVal {
+			ov.val = "true"
+			ov.needVal = !clapIsBoolFlag(o.value)
+		}
+		ovs = append(ovs, ov)
+
+		for _, ov := range ovs {
+			if ov.needVal {
+				if i+1 == len(args) {
+					return nil, &ClapError{Kind: ClapKindMissingOptionValue, Name: ov.name}
+				}
+				i++
+				ov.val = args[i]
+			}
+			if err := ov.o.value.Set(ov.val); err != nil {
+				return nil, &ClapError{Kind: ClapKindInvalidOptionValue, Name: ov.name, Value: ov.val, Err: err}
+			}
+		}
+	}
 
 	if len(cc.args) > 0 {
 		for i := range cc.args {
@@ -76,34 +191,57 @@ func (cc *clapCommand) parse(args []string) ([]string, error) {
 			arg := &cc.args[i]
 			if len(rest) <= i {
 				if arg.required {
-					return nil, fmt.Errorf("missing required arg '%s'", arg.name)
+					return nil, &ClapError{Kind: ClapKindMissingArg, Name: arg.name}
 				}
-				return nil, nil
+				break
 			}
 			if err := arg.value.Set(rest[i]); err != nil {
-				return nil, fmt.Errorf("parsing positional argument '%s': %v", arg.name, err)
+				return nil, &ClapError{Kind: ClapKindInvalidArgValue, Name: arg.name, Value: rest[i], Err: err}
 			}
 		}
-		return nil, nil
+		if len(rest) > len(cc.args) {
+			rest = rest[len(cc.args):]
+		} else {
+			rest = nil
+		}
 	}
 
-	return rest, nil
+	if len(rest) > 0 {
+		return nil, &ClapError{Kind: ClapKindUnexpectedArg, Value: rest[0]}
+	}
+	return nil, nil
 }
 
-type clapString string
+func (cc *clapCommand) findOpt(name string) *clapInput {
+	for i := range cc.opts {
+		if cc.opts[i].name == name {
+			return &cc.opts[i]
+		}
+	}
+	return nil
+}
+
+func clapIsBoolFlag(v flag.Value) bool {
+	bf, ok := v.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+type clapString[T ~string] struct{ v *T }
 
-func clapNewString(p *string) *clapString { return (*clapString)(p) }
+func clapNewString[T ~string](p *T) clapString[T] { return clapString[T]{p} }
 
-func (v *clapString) String() string { return string(*v) }
+func (v clapString[T]) String() string { return string(*v.v) }
 
-func (v *clapString) Set(s string) error {
-	*v = clapString(s)
+func (v clapString[T]) Set(s string) error {
+	*v.v = T(s)
 	return nil
 }
 
-type clapUint[T uint | uint8 | uint16 | uint32 | uint64] struct{ v *T }
+type clapUint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64] struct{ v *T }
 
-func clapNewUint[T uint | uint8 | uint16 | uint32 | uint64](p *T) clapUint[T] { return clapUint[T]{p} }
+func clapNewUint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](p *T) clapUint[T] {
+	return clapUint[T]{p}
+}
 
 func (v clapUint[T]) String() string { return strconv.FormatUint(uint64(*v.v), 10) }
 
@@ -139,11 +277,16 @@ arguments:
 }
 
 func (c *mycli) Parse(args []string) {
+	if err := c.ParseArgs(args); err != nil {
+		clapExit(err)
+	}
+}
+
+func (c *mycli) ParseArgs(args []string) error {
 	p := clapCommand{
-		usage: c.UsageHelp,
 		opts: []clapInput{
-			{name: "prefix", value: clapNewString(&c.prefix), envName: "MY_PREFIX"},
-			{name: "count", value: clapNewUint(&c.count), envName: "MY_COUNT"},
+			{name: "-prefix", value: clapNewString(&c.prefix), envName: "MY_PREFIX"},
+			{name: "-count", value: clapNewUint(&c.count), envName: "MY_COUNT"},
 		},
 		args: []clapInput{
 			{name: "[input]", value: clapNewString(&c.input), envName: "MY_INPUT"},
@@ -151,6 +294,9 @@ func (c *mycli) Parse(args []string) {
 	}
 	_, err := p.parse(args)
 	if err != nil {
-		clapFatalf("simple_env", err.Error())
+		err.CmdPath = "simple_env"
+		err.usage = c.UsageHelp
+		return err
 	}
+	return nil
 }
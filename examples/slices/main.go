@@ -0,0 +1,37 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Print a greeting for each name.
+type mycli struct {
+	// A name to greet (can be repeated).
+	//
+	// clap:opt name
+	// clap:opt_arg_name name
+	names []string
+	// How many exclamation marks to end each greeting with.
+	//
+	// clap:opt marks
+	// clap:opt_arg_name n
+	// clap:opt_sep ,
+	marks []uint
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	for i, name := range c.names {
+		var n uint
+		if i < len(c.marks) {
+			n = c.marks[i]
+		}
+		fmt.Println("Hello, " + name + strings.Repeat("!", int(n)))
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Print the time that comes a duration after a start time.
+type mycli struct {
+	// The time to start from.
+	//
+	// clap:opt from
+	// clap:opt_time_layout DateTime
+	// clap:default 2024-01-01 00:00:00
+	from time.Time
+	// Round the result to a multiple of this duration.
+	//
+	// clap:opt round
+	// clap:default 1s
+	round time.Duration
+	// How long after the start time.
+	//
+	// clap:arg_required
+	dur time.Duration
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	fmt.Println(c.from.Add(c.dur).Round(c.round).Format(time.DateTime))
+}
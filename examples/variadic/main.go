@@ -0,0 +1,36 @@
+package main
+
+//go:generate goclap -type mycli
+
+import (
+	"fmt"
+	"os"
+)
+
+// Add up some numbers.
+type mycli struct {
+	// Print the average instead of the sum.
+	//
+	// clap:opt avg
+	avg bool
+	// The first number.
+	//
+	// clap:arg_required
+	first float64
+	// Any other numbers.
+	rest []float64
+}
+
+func main() {
+	c := mycli{}
+	c.Parse(os.Args[1:])
+
+	sum := c.first
+	for _, n := range c.rest {
+		sum += n
+	}
+	if c.avg {
+		sum /= float64(1 + len(c.rest))
+	}
+	fmt.Println(sum)
+}
@@ -3,10 +3,16 @@ package main
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
+	"go/format"
+	"go/scanner"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
 var (
@@ -20,33 +26,60 @@ var (
 	parseFnTmplText string
 )
 
-func generate(incVersion bool, pkgName string, usgTextWidth int, usgLayoutKind string, root *command) ([]byte, error) {
-	g, err := newGenerator(usgTextWidth, usgLayoutKind)
+func generate(incVersion bool, pkgName string, usgTextWidth int, usgLayoutKind string, optStyle string, root *command) ([]byte, error) {
+	g, err := newGenerator(usgTextWidth, usgLayoutKind, optStyle)
 	if err != nil {
 		return nil, fmt.Errorf("initializing generator: %w", err)
 	}
-	if err = g.writeBase(incVersion, pkgName, root); err != nil {
+	imports := root.assignImportNames()
+	if err = g.writeBase(incVersion, pkgName, imports, root); err != nil {
 		return nil, err
 	}
 	if err = g.genCommandCode(root); err != nil {
 		return nil, err
 	}
-	return g.buf.Bytes(), nil
+	return formatCode(g.buf.Bytes())
+}
+
+// formatCode runs the generated code through gofmt. The code should always parse, so an
+// error here means there's a bug in one of the templates. The error includes the line of
+// generated code where parsing failed to make that easier to track down.
+func formatCode(code []byte) ([]byte, error) {
+	formatted, err := format.Source(code)
+	if err == nil {
+		return formatted, nil
+	}
+	var errList scanner.ErrorList
+	if !errors.As(err, &errList) || len(errList) == 0 {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	first := errList[0]
+	lines := strings.Split(string(code), "\n")
+	var src string
+	if n := first.Pos.Line; n > 0 && n <= len(lines) {
+		src = fmt.Sprintf("\n%6d | %s", n, lines[n-1])
+	}
+	return nil, fmt.Errorf("generated code doesn't parse (this is a bug in goclap): %d:%d: %s%s",
+		first.Pos.Line, first.Pos.Column, first.Msg, src)
 }
 
 type generator struct {
 	buf           bytes.Buffer
 	usgTextWidth  int
 	usgLayoutKind string
+	gnuStyle      bool
 	usgFnTmpl     *template.Template
 	parseFnTmpl   *template.Template
 }
 
-func newGenerator(usgTextWidth int, usgLayoutKind string) (generator, error) {
+func newGenerator(usgTextWidth int, usgLayoutKind string, optStyle string) (generator, error) {
+	gnuStyle := optStyle == "gnu"
 	usgFnTmpl := template.Must(template.New("usagefunc").Parse(usgFnTmplText))
 
 	parseFuncs := template.FuncMap{
-		"add": func(a, b int) int { return a + b },
+		"add":      func(a, b int) int { return a + b },
+		"optName":  func(o option) string { return o.runtimeName(gnuStyle) },
+		"optShort": func(o option) string { return o.runtimeShort() },
 	}
 	parseFnTmpl, err := template.New("parsefunc").Funcs(parseFuncs).Parse(parseFnTmplText)
 	if err != nil {
@@ -56,25 +89,44 @@ func newGenerator(usgTextWidth int, usgLayoutKind string) (generator, error) {
 	return generator{
 		usgTextWidth:  usgTextWidth,
 		usgLayoutKind: usgLayoutKind,
+		gnuStyle:      gnuStyle,
 		usgFnTmpl:     usgFnTmpl,
 		parseFnTmpl:   parseFnTmpl,
 	}, nil
 }
 
 type headerData struct {
-	PkgName      string
-	Version      string
-	HasBool      bool
-	HasFloat     bool
-	HasInt       bool
-	HasUint      bool
-	HasNumber    bool
-	HasSubcmds   bool
-	Types        typeSet
-	NeedsEnvCode bool
-}
-
-func (g *generator) writeBase(incVersion bool, pkgName string, root *command) error {
+	PkgName         string
+	Imports         []string
+	Version         string
+	GNU             bool
+	HasBool         bool
+	HasFloat        bool
+	HasInt          bool
+	HasUint         bool
+	HasNumber       bool
+	HasTime         bool
+	HasText         bool
+	HasBinary       bool
+	HasPointer      bool
+	HasChoices      bool
+	HasChoiceSlice  bool
+	HasBounds       bool
+	HasBoundsSlice  bool
+	HasMathLimits   bool
+	HasCompleteFunc bool
+	HasSubcmds      bool
+	HasSlice        bool
+	HasVariadicArg  bool
+	AllowsExtraArgs bool
+	Types           typeSet
+	NeedsEnvCode    bool
+	HasReqOpts      bool
+	HasShortOpts    bool
+	HasGlobalOpts   bool
+}
+
+func (g *generator) writeBase(incVersion bool, pkgName string, imports []string, root *command) error {
 	ts := typeSet{}
 	root.getTypes(ts)
 
@@ -83,15 +135,33 @@ func (g *generator) writeBase(incVersion bool, pkgName string, root *command) er
 	hasUint := ts.HasAny("uint", "uint8", "uint16", "uint32", "uint64")
 
 	data := headerData{
-		PkgName:      pkgName,
-		Types:        ts,
-		HasBool:      ts.HasAny("bool"),
-		HasFloat:     hasFloat,
-		HasInt:       hasInt,
-		HasUint:      hasUint,
-		HasNumber:    hasFloat || hasInt || hasUint,
-		HasSubcmds:   root.HasSubcmds(),
-		NeedsEnvCode: root.HasEnvArgOrOptSomewhere(),
+		PkgName:         pkgName,
+		Imports:         imports,
+		GNU:             g.gnuStyle,
+		Types:           ts,
+		HasBool:         ts.HasAny("bool"),
+		HasFloat:        hasFloat,
+		HasInt:          hasInt,
+		HasUint:         hasUint,
+		HasNumber:       hasFloat || hasInt || hasUint,
+		HasTime:         ts.HasAny("time.Duration", "time.Time"),
+		HasText:         ts.HasAny("encoding.TextUnmarshaler"),
+		HasBinary:       ts.HasAny("encoding.BinaryUnmarshaler"),
+		HasPointer:      ts.HasPointer(),
+		HasChoices:      root.HasChoicesSomewhere(false),
+		HasChoiceSlice:  root.HasChoicesSomewhere(true),
+		HasBounds:       root.HasBoundsSomewhere(false),
+		HasBoundsSlice:  root.HasBoundsSomewhere(true),
+		HasMathLimits:   root.HasMathLimitsSomewhere(),
+		HasCompleteFunc: root.HasCompleteFuncSomewhere(),
+		HasSubcmds:      root.HasSubcmds(),
+		HasSlice:        ts.HasSlice(),
+		HasVariadicArg:  root.HasVariadicArgSomewhere(),
+		AllowsExtraArgs: root.AllowsExtraArgsSomewhere(),
+		NeedsEnvCode:    root.HasEnvArgOrOptSomewhere(),
+		HasReqOpts:      root.HasReqOptSomewhere(),
+		HasShortOpts:    root.HasShortOptSomewhere(),
+		HasGlobalOpts:   root.HasGlobalOptSomewhere(),
 	}
 	if incVersion {
 		data.Version = getBuildVersionInfo().String()
@@ -104,14 +174,90 @@ func (g *generator) writeBase(incVersion bool, pkgName string, root *command) er
 	return nil
 }
 
+// generatedImports are the names of the packages that the generated code might import
+// besides those of command types.
+var generatedImports = []string{
+	"encoding", "errors", "flag", "fmt", "math", "os", "reflect", "slices", "strconv",
+	"strings", "time",
+}
+
+// assignImportNames sets the name that the generated code imports the package of each
+// command type from another package as, and it returns the import specs for them. That's
+// the package's own name, followed by a number if the name is already taken.
+func (c *command) assignImportNames() []string {
+	taken := make(map[string]string) // Import paths by name.
+	for _, name := range generatedImports {
+		taken[name] = name
+	}
+	var specs []string
+	var walk func(c *command)
+	walk = func(c *command) {
+		if c.pkgPath != "" {
+			name := c.pkgName
+			for i := 2; taken[name] != "" && taken[name] != c.pkgPath; i++ {
+				name = c.pkgName + strconv.Itoa(i)
+			}
+			if taken[name] == "" {
+				taken[name] = c.pkgPath
+				spec := strconv.Quote(c.pkgPath)
+				if name != c.pkgName {
+					spec = name + " " + spec
+				}
+				specs = append(specs, spec)
+			}
+			c.importName = name
+		}
+		for i := range c.Subcmds {
+			walk(&c.Subcmds[i])
+		}
+	}
+	walk(c)
+	return specs
+}
+
+// RecvType returns the name of the type that the generated methods for this command are
+// declared on. That's the command's own type unless it's from another package, in which
+// case it's a local type defined as that one (such as `type clapCliGet cli.Get`).
+func (c *command) RecvType() string {
+	if c.pkgPath == "" {
+		return c.TypeName
+	}
+	return "clap" + exportedName(c.importName) + c.TypeName
+}
+
+// QualifiedType returns the command's type as the generated code refers to it.
+func (c *command) QualifiedType() string {
+	if c.pkgPath == "" {
+		return c.TypeName
+	}
+	return c.importName + "." + c.TypeName
+}
+
+// FieldRecv returns the Go expression for this subcommand's field as the receiver of the
+// generated methods.
+func (c *command) FieldRecv() string {
+	if c.pkgPath == "" {
+		return "c." + c.FieldName
+	}
+	return fmt.Sprintf("(*%s)(c.%s)", c.RecvType(), c.FieldName)
+}
+
+// exportedName returns the given name with its first letter in uppercase.
+func exportedName(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
 func (c *command) getTypes(ts typeSet) {
 	for _, o := range c.Opts {
 		if o.Name != "h" {
 			ts[o.FieldType] = struct{}{}
+			ts[o.FieldType.ElemType()] = struct{}{}
 		}
 	}
 	for _, a := range c.Args {
 		ts[a.FieldType] = struct{}{}
+		ts[a.FieldType.ElemType()] = struct{}{}
 	}
 	for _, sc := range c.Subcmds {
 		sc.getTypes(ts)
@@ -129,8 +275,28 @@ func (ts typeSet) HasAny(names ...basicType) bool {
 	return false
 }
 
+func (ts typeSet) HasSlice() bool {
+	for t := range ts {
+		if t.IsSlice() {
+			return true
+		}
+	}
+	return false
+}
+
+func (ts typeSet) HasPointer() bool {
+	for t := range ts {
+		if t.IsPointer() {
+			return true
+		}
+	}
+	return false
+}
+
+// ClapValueType returns the name of the generated value type (minus the "clap" prefix)
+// for this type. For slices and pointers, it's the value type of the element.
 func (t basicType) ClapValueType() string {
-	switch t {
+	switch t.ElemType() {
 	case "bool":
 		return "Bool"
 	case "string":
@@ -141,6 +307,16 @@ func (t basicType) ClapValueType() string {
 		return "Int"
 	case "uint", "uint8", "uint16", "uint32", "uint64", "byte":
 		return "Uint"
+	case "time.Duration":
+		return "Duration"
+	case "time.Time":
+		return "Time"
+	case "flag.Value":
+		return "Value"
+	case "encoding.TextUnmarshaler":
+		return "Text"
+	case "encoding.BinaryUnmarshaler":
+		return "Binary"
 	default:
 		panic("unknown basic type: " + t)
 	}
@@ -162,56 +338,18 @@ func (g *generator) genCommandCode(c *command) error {
 }
 
 type usgTmplData struct {
-	OptUsgs    []string
-	ArgUsgs    []string
-	SubcmdUsgs []string
+	UsgLines      []string
+	OptUsgs       []string
+	GlobalOptUsgs []string
+	ArgUsgs       []string
+	SubcmdUsgs    []string
 
 	*command
 }
 
 func (g *generator) genCmdUsageFunc(c *command) error {
-	optUsgs := make([]string, len(c.Opts))
-	{
-		var optNameColWidth int
-		for _, o := range c.Opts {
-			if l := len(o.usgNameAndArg()); l > optNameColWidth {
-				optNameColWidth = l
-			}
-		}
-		for i, o := range c.Opts {
-			switch g.usgLayoutKind {
-			case "roomy":
-				var extra string
-				{
-					if v, ok := o.data.getConfig("default"); ok {
-						extra += "\n      [default: " + v + "]"
-					}
-					if v, ok := o.data.getConfig("env"); ok {
-						extra += "\n      [env: " + v + "]"
-					}
-				}
-				content := "   " + o.usgNameAndArg() + "\n"
-				content += "      " + wrapBlurb(o.data.Blurb, 6, g.usgTextWidth)
-				if extra != "" {
-					content += "\n" + extra
-				}
-				if i < len(c.Opts)-1 {
-					content += "\n"
-				}
-				optUsgs[i] = content
-			default:
-				paddedNameAndArg := fmt.Sprintf("   %-*s   ", optNameColWidth, o.usgNameAndArg())
-				desc := o.data.Blurb
-				if v, ok := o.data.getConfig("default"); ok {
-					desc += " (default: " + v + ")"
-				}
-				if v, ok := o.data.getConfig("env"); ok {
-					desc += " [$" + v + "]"
-				}
-				optUsgs[i] = paddedNameAndArg + wrapBlurb(desc, len(paddedNameAndArg), g.usgTextWidth)
-			}
-		}
-	}
+	optUsgs := g.optUsgs(c.Opts)
+	globalOptUsgs := g.optUsgs(c.InheritedOpts)
 
 	argUsgs := make([]string, len(c.Args))
 	{
@@ -226,6 +364,12 @@ func (g *generator) genCmdUsageFunc(c *command) error {
 			case "roomy":
 				var extra string
 				{
+					if v := a.data.choices(); v != nil {
+						extra += "\n      [possible values: " + strings.Join(v, ", ") + "]"
+					}
+					if v := a.data.boundsText(); v != "" {
+						extra += "\n      [" + v + "]"
+					}
 					if v, ok := a.data.getConfig("default"); ok {
 						extra += "\n      [default: " + v + "]"
 					}
@@ -245,6 +389,12 @@ func (g *generator) genCmdUsageFunc(c *command) error {
 			default:
 				paddedName := fmt.Sprintf("   %-*s   ", argNameColWidth, a.UsgName())
 				desc := a.data.Blurb
+				if v := a.data.choices(); v != nil {
+					desc += " [possible values: " + strings.Join(v, ", ") + "]"
+				}
+				if v := a.data.boundsText(); v != "" {
+					desc += " [" + v + "]"
+				}
 				if v, ok := a.data.getConfig("default"); ok {
 					desc += " (default: " + v + ")"
 				}
@@ -281,10 +431,12 @@ func (g *generator) genCmdUsageFunc(c *command) error {
 	}
 
 	err := g.usgFnTmpl.Execute(&g.buf, usgTmplData{
-		OptUsgs:    optUsgs,
-		ArgUsgs:    argUsgs,
-		SubcmdUsgs: subcmdUsgs,
-		command:    c,
+		UsgLines:      c.UsageLines(g.gnuStyle),
+		OptUsgs:       optUsgs,
+		GlobalOptUsgs: globalOptUsgs,
+		ArgUsgs:       argUsgs,
+		SubcmdUsgs:    subcmdUsgs,
+		command:       c,
 	})
 	if err != nil {
 		return err
@@ -292,6 +444,63 @@ func (g *generator) genCmdUsageFunc(c *command) error {
 	return nil
 }
 
+// optUsgs returns the usage message entries for the given options.
+func (g *generator) optUsgs(opts []option) []string {
+	optUsgs := make([]string, len(opts))
+	var optNameColWidth int
+	for _, o := range opts {
+		if l := len(o.packedUsgNameAndArg(g.gnuStyle)); l > optNameColWidth {
+			optNameColWidth = l
+		}
+	}
+	for i, o := range opts {
+		switch g.usgLayoutKind {
+		case "roomy":
+			var extra string
+			{
+				if v := o.data.choices(); v != nil {
+					extra += "\n      [possible values: " + strings.Join(v, ", ") + "]"
+				}
+				if v := o.data.boundsText(); v != "" {
+					extra += "\n      [" + v + "]"
+				}
+				if v, ok := o.data.getConfig("default"); ok {
+					extra += "\n      [default: " + v + "]"
+				}
+				if v, ok := o.data.getConfig("env"); ok {
+					extra += "\n      [env: " + v + "]"
+				}
+			}
+			content := "   " + o.usgNameAndArg(g.gnuStyle) + "\n"
+			content += "      " + wrapBlurb(o.data.Blurb, 6, g.usgTextWidth)
+			if extra != "" {
+				content += "\n" + extra
+			}
+			if i < len(opts)-1 {
+				content += "\n"
+			}
+			optUsgs[i] = content
+		default:
+			paddedNameAndArg := fmt.Sprintf("   %-*s   ", optNameColWidth, o.packedUsgNameAndArg(g.gnuStyle))
+			desc := o.data.Blurb
+			if v := o.data.choices(); v != nil {
+				desc += " [possible values: " + strings.Join(v, ", ") + "]"
+			}
+			if v := o.data.boundsText(); v != "" {
+				desc += " [" + v + "]"
+			}
+			if v, ok := o.data.getConfig("default"); ok {
+				desc += " (default: " + v + ")"
+			}
+			if v, ok := o.data.getConfig("env"); ok {
+				desc += " [$" + v + "]"
+			}
+			optUsgs[i] = paddedNameAndArg + wrapBlurb(desc, len(paddedNameAndArg), g.usgTextWidth)
+		}
+	}
+	return optUsgs
+}
+
 func (g *generator) genCmdParseFunc(c *command) error {
 	err := g.parseFnTmpl.Execute(&g.buf, c)
 	if err != nil {
@@ -304,17 +513,195 @@ func (c *command) Defaults() string {
 	var s string
 	for _, o := range c.Opts {
 		if defVal, ok := o.data.getConfig("default"); ok {
-			s += fmt.Sprintf("\tc.%s = %s\n", o.FieldName, defVal)
+			s += fmt.Sprintf("\tc.%s = %s\n", o.FieldName, defaultExpr(o.FieldType, defVal, o.timeLayout()))
 		}
 	}
 	for _, a := range c.Args {
 		if defVal, ok := a.data.getConfig("default"); ok {
-			s += fmt.Sprintf("\tc.%s = %s\n", a.FieldName, defVal)
+			s += fmt.Sprintf("\tc.%s = %s\n", a.FieldName, defaultExpr(a.FieldType, defVal, a.timeLayout()))
 		}
 	}
 	return s
 }
 
+// defaultExpr returns the Go expression for a 'clap:default' value. Defaults for
+// `time.Duration` and `time.Time` fields can be given the same way they'd be given on the
+// command line (e.g. "5s"), in which case they're converted to Go code here. Otherwise,
+// the default is assumed to already be a Go expression.
+func defaultExpr(t basicType, v string, layout timeLayout) string {
+	switch t {
+	case "time.Duration":
+		if d, err := time.ParseDuration(v); err == nil {
+			return fmt.Sprintf("time.Duration(%d)", d)
+		}
+	case "time.Time":
+		if tm, err := time.Parse(layout.value, v); err == nil {
+			loc := "time.UTC"
+			if _, offset := tm.Zone(); offset != 0 {
+				loc = fmt.Sprintf("time.FixedZone(\"\", %d)", offset)
+			}
+			return fmt.Sprintf("time.Date(%d, %d, %d, %d, %d, %d, %d, %s)",
+				tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), loc)
+		}
+	}
+	return v
+}
+
+// timeLayout is the layout used to parse a `time.Time` option or argument.
+type timeLayout struct {
+	code  string // The Go expression (e.g. `time.RFC3339` or `"2006-01-02"`).
+	value string // The actual layout string.
+}
+
+// namedTimeLayouts are the layout constants of the `time` package that can be referred to
+// by name in a time layout directive (e.g. `clap:opt_time_layout DateOnly`).
+var namedTimeLayouts = map[string]string{
+	"Layout":      time.Layout,
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+func parseTimeLayout(v string) timeLayout {
+	if v == "" {
+		v = "RFC3339"
+	}
+	if l, ok := namedTimeLayouts[v]; ok {
+		return timeLayout{code: "time." + v, value: l}
+	}
+	return timeLayout{code: strconv.Quote(v), value: v}
+}
+
+func (o *option) timeLayout() timeLayout {
+	v, _ := o.data.getConfig("opt_time_layout")
+	return parseTimeLayout(v)
+}
+
+func (a *argument) timeLayout() timeLayout {
+	v, _ := a.data.getConfig("arg_time_layout")
+	return parseTimeLayout(v)
+}
+
+// NewValue returns the Go expression that creates the `flag.Value` for this option.
+func (o *option) NewValue() string {
+	sep, _ := o.data.getConfig("opt_sep")
+	if choices := o.data.choices(); choices != nil {
+		return newChoiceExpr(o.FieldType, o.FieldName, sep, choices)
+	}
+	if minVal, maxVal := o.data.bounds(); minVal != "" || maxVal != "" {
+		return newBoundedExpr(o.FieldType, o.FieldName, sep, minVal, maxVal)
+	}
+	return newValueExpr(o.FieldType, o.FieldName, sep, o.timeLayout())
+}
+
+// NewValue returns the Go expression that creates the `flag.Value` for this argument.
+func (a *argument) NewValue() string {
+	if choices := a.data.choices(); choices != nil {
+		return newChoiceExpr(a.FieldType, a.FieldName, "", choices)
+	}
+	if minVal, maxVal := a.data.bounds(); minVal != "" || maxVal != "" {
+		return newBoundedExpr(a.FieldType, a.FieldName, "", minVal, maxVal)
+	}
+	return newValueExpr(a.FieldType, a.FieldName, "", a.timeLayout())
+}
+
+func newValueExpr(t basicType, fieldName, sep string, layout timeLayout) string {
+	ctor := "clapNew" + t.ClapValueType()
+	switch t {
+	case "flag.Value":
+		// The field itself already is a `flag.Value` (through a pointer receiver).
+		return "&c." + fieldName
+	case "time.Time":
+		return fmt.Sprintf("clapNewTime(&c.%s, %s)", fieldName, layout.code)
+	}
+	if t.ElemType() == "time.Time" {
+		ctor = fmt.Sprintf("func(p *time.Time) clapTime { return clapNewTime(p, %s) }", layout.code)
+	}
+	switch {
+	case t.IsSlice():
+		return fmt.Sprintf("clapNewSlice(&c.%s, %s, %s)", fieldName, strconv.Quote(sep), ctor)
+	case t.IsPointer():
+		return fmt.Sprintf("clapNewPtr(&c.%s, %s)", fieldName, ctor)
+	}
+	return fmt.Sprintf("%s(&c.%s)", ctor, fieldName)
+}
+
+// newChoiceExpr returns the Go expression that creates the `flag.Value` for a string (or
+// string slice) field that only accepts the given choices.
+func newChoiceExpr(t basicType, fieldName, sep string, choices []string) string {
+	quoted := make([]string, len(choices))
+	for i := range choices {
+		quoted[i] = strconv.Quote(choices[i])
+	}
+	if t.IsSlice() {
+		return fmt.Sprintf("clapNewChoiceSlice(&c.%s, %s, %s)", fieldName, strconv.Quote(sep), strings.Join(quoted, ", "))
+	}
+	return fmt.Sprintf("clapNewChoice(&c.%s, %s)", fieldName, strings.Join(quoted, ", "))
+}
+
+// numLimits are the Go expressions of the lowest and highest values of each numeric type.
+// They're used in place of any bound that isn't given by a 'clap:min' or 'clap:max'.
+var numLimits = map[basicType][2]string{
+	"int":     {"math.MinInt", "math.MaxInt"},
+	"int8":    {"math.MinInt8", "math.MaxInt8"},
+	"int16":   {"math.MinInt16", "math.MaxInt16"},
+	"int32":   {"math.MinInt32", "math.MaxInt32"},
+	"rune":    {"math.MinInt32", "math.MaxInt32"},
+	"int64":   {"math.MinInt64", "math.MaxInt64"},
+	"uint":    {"0", "math.MaxUint"},
+	"uint8":   {"0", "math.MaxUint8"},
+	"byte":    {"0", "math.MaxUint8"},
+	"uint16":  {"0", "math.MaxUint16"},
+	"uint32":  {"0", "math.MaxUint32"},
+	"uint64":  {"0", "math.MaxUint64"},
+	"float32": {"-math.MaxFloat32", "math.MaxFloat32"},
+	"float64": {"-math.MaxFloat64", "math.MaxFloat64"},
+}
+
+// newBoundedExpr returns the Go expression that creates the `flag.Value` for a numeric (or
+// numeric slice) field that only accepts values within the given bounds.
+func newBoundedExpr(t basicType, fieldName, sep, minVal, maxVal string) string {
+	limits := numLimits[t.ElemType()]
+	if minVal == "" {
+		minVal = limits[0]
+	}
+	if maxVal == "" {
+		maxVal = limits[1]
+	}
+	ctor := "clapNew" + t.ClapValueType()
+	if t.IsSlice() {
+		return fmt.Sprintf("clapNewBoundedSlice(&c.%s, %s, %s, %s, %s)", fieldName, strconv.Quote(sep), ctor, minVal, maxVal)
+	}
+	return fmt.Sprintf("clapNewBounded(&c.%s, %s, %s, %s)", fieldName, ctor, minVal, maxVal)
+}
+
+// usesMathLimit returns true if there's a 'clap:min' or 'clap:max' directive for a field of
+// the given type, but the other bound is missing and falls back to a `math` limit.
+func (d *clapData) usesMathLimit(t basicType) bool {
+	minVal, maxVal := d.bounds()
+	if minVal == "" && maxVal == "" {
+		return false
+	}
+	limits := numLimits[t.ElemType()]
+	return (minVal == "" && strings.HasPrefix(strings.TrimPrefix(limits[0], "-"), "math.")) ||
+		(maxVal == "" && strings.HasPrefix(limits[1], "math."))
+}
+
 func (c *command) Parents() string {
 	s := ""
 	for i := range c.parentNames {
@@ -323,7 +710,7 @@ func (c *command) Parents() string {
 	return s
 }
 
-func (c *command) UsageLines() []string {
+func (c *command) UsageLines(gnuStyle bool) []string {
 	var us []string
 	for _, cfg := range c.Data.configs {
 		if cfg.key == "cmd_usage" {
@@ -334,16 +721,22 @@ func (c *command) UsageLines() []string {
 		return us
 	}
 	optionsSlot := " [options]" // Every command has at least the help options for now.
-	commandSlot := ""
-	if c.HasSubcmds() {
-		commandSlot = " <command>"
+	for i := range c.Opts {
+		if c.Opts[i].IsRequired() {
+			optionsSlot += " " + c.Opts[i].runtimeName(gnuStyle) + " " + c.Opts[i].usgArgName()
+		}
 	}
 	argsSlot := ""
 	for i := range c.Args {
 		argsSlot += " " + c.Args[i].UsgName()
 	}
+	// Any arguments come before the subcommand, which takes all that follows.
+	commandSlot := ""
+	if c.HasSubcmds() {
+		commandSlot = " <command>"
+	}
 	return []string{
-		c.UsgName() + optionsSlot + commandSlot + argsSlot,
+		c.UsgName() + optionsSlot + argsSlot + commandSlot,
 	}
 }
 
@@ -380,6 +773,20 @@ func (c *command) Overview() string {
 	return s.String()
 }
 
+// CompleteFunc returns the name of the function (if any) that provides completion
+// candidates for this option's value.
+func (o *option) CompleteFunc() string {
+	v, _ := o.data.getConfig("complete_func")
+	return v
+}
+
+// CompleteFunc returns the name of the function (if any) that provides completion
+// candidates for this argument.
+func (a *argument) CompleteFunc() string {
+	v, _ := a.data.getConfig("complete_func")
+	return v
+}
+
 func (o *option) EnvVar() string {
 	name, _ := o.data.getConfig("env")
 	return name
@@ -396,18 +803,85 @@ func (a *argument) UsgName() string {
 		name = v
 	}
 	if a.IsRequired() {
-		return "<" + name + ">"
+		name = "<" + name + ">"
+	} else {
+		name = "[" + name + "]"
+	}
+	if a.IsVariadic() {
+		name += "..."
 	}
-	return "[" + name + "]"
+	return name
 }
 
+// IsVariadic returns true if this argument is a slice that takes all of the remaining
+// positional arguments.
+func (a *argument) IsVariadic() bool { return a.FieldType.IsSlice() }
+
 func (a *argument) IsRequired() bool {
 	_, ok := a.data.getConfig("arg_required")
 	return ok
 }
 
-func (o *option) usgNameAndArg() string {
-	s := "-" + o.Name
+// IsGlobal returns true if this option is accepted anywhere in the subcommand chain.
+func (o *option) IsGlobal() bool {
+	_, ok := o.data.getConfig("opt_global")
+	return ok
+}
+
+func (o *option) IsRequired() bool {
+	_, ok := o.data.getConfig("opt_required")
+	return ok
+}
+
+// runtimeName returns the primary name of this option as it's given on the command line.
+// For GNU style, multi-character names get two dashes and single character names get one.
+// Otherwise, every name gets a single dash.
+func (o *option) runtimeName(gnuStyle bool) string {
+	if !gnuStyle || len(o.Name) == 1 {
+		return "-" + o.Name
+	}
+	return "--" + o.Name
+}
+
+// runtimeShort returns this option's short name (if any) as it's given on the command line.
+func (o *option) runtimeShort() string {
+	if o.Short == "" {
+		return ""
+	}
+	return "-" + o.Short
+}
+
+// usgNames returns this option's names as they appear in the usage message, such as
+// `-v, --verbose` for GNU style.
+func (o *option) usgNames(gnuStyle bool) string {
+	if !gnuStyle {
+		if o.Short != "" {
+			return "-" + o.Short + ", -" + o.Name
+		}
+		return "-" + o.Name
+	}
+	if o.Name == "h" {
+		return "-h, --help"
+	}
+	if o.Short != "" {
+		return "-" + o.Short + ", " + o.runtimeName(true)
+	}
+	return o.runtimeName(true)
+}
+
+// packedUsgNameAndArg is usgNameAndArg for the packed layout. GNU style options without a
+// short name are indented so that their long names line up with the long names of those
+// that do have short names. That includes single character long names even though they
+// only get one dash.
+func (o *option) packedUsgNameAndArg(gnuStyle bool) string {
+	if gnuStyle && o.Short == "" && o.Name != "h" {
+		return "    " + o.usgNameAndArg(gnuStyle)
+	}
+	return o.usgNameAndArg(gnuStyle)
+}
+
+func (o *option) usgNameAndArg(gnuStyle bool) string {
+	s := o.usgNames(gnuStyle)
 	if an := o.usgArgName(); an != "" {
 		s += "  " + an
 	}
@@ -421,10 +895,100 @@ func (o *option) usgArgName() string {
 	if o.FieldType.IsBool() {
 		return ""
 	}
-	if name, ok := o.data.getConfig("opt_arg_name"); ok {
-		return "<" + name + ">"
+	name := "arg"
+	if v, ok := o.data.getConfig("opt_arg_name"); ok {
+		name = v
 	}
-	return "<arg>"
+	if o.FieldType.IsSlice() {
+		return "<" + name + ">..."
+	}
+	return "<" + name + ">"
+}
+
+// HasChoicesSomewhere returns true if this command or one of its subcommands contains an
+// option or an argument with a 'clap:choices' directive. If onSlice is true, only slice
+// options or arguments are considered.
+func (c *command) HasChoicesSomewhere(onSlice bool) bool {
+	for i := range c.Opts {
+		if c.Opts[i].data.choices() != nil && (!onSlice || c.Opts[i].FieldType.IsSlice()) {
+			return true
+		}
+	}
+	for i := range c.Args {
+		if c.Args[i].data.choices() != nil && (!onSlice || c.Args[i].FieldType.IsSlice()) {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasChoicesSomewhere(onSlice) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasBoundsSomewhere returns true if this command or one of its subcommands contains an
+// option or an argument with a 'clap:min' or 'clap:max' directive. If onSlice is true,
+// only slice options or arguments are considered.
+func (c *command) HasBoundsSomewhere(onSlice bool) bool {
+	for i := range c.Opts {
+		if c.Opts[i].data.boundsText() != "" && (!onSlice || c.Opts[i].FieldType.IsSlice()) {
+			return true
+		}
+	}
+	for i := range c.Args {
+		if c.Args[i].data.boundsText() != "" && (!onSlice || c.Args[i].FieldType.IsSlice()) {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasBoundsSomewhere(onSlice) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMathLimitsSomewhere returns true if this command or one of its subcommands contains a
+// bounded option or argument with a missing bound that falls back to a `math` limit.
+func (c *command) HasMathLimitsSomewhere() bool {
+	for i := range c.Opts {
+		if c.Opts[i].data.usesMathLimit(c.Opts[i].FieldType) {
+			return true
+		}
+	}
+	for i := range c.Args {
+		if c.Args[i].data.usesMathLimit(c.Args[i].FieldType) {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasMathLimitsSomewhere() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCompleteFuncSomewhere returns true if this command or one of its subcommands contains
+// an option or an argument with a 'clap:complete_func' directive.
+func (c *command) HasCompleteFuncSomewhere() bool {
+	for i := range c.Opts {
+		if c.Opts[i].CompleteFunc() != "" {
+			return true
+		}
+	}
+	for i := range c.Args {
+		if c.Args[i].CompleteFunc() != "" {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasCompleteFuncSomewhere() {
+			return true
+		}
+	}
+	return false
 }
 
 // HasEnvArgOrOptSomewhere returns true if this command or one of its subcommands contains
@@ -448,6 +1012,90 @@ func (c *command) HasEnvArgOrOptSomewhere() bool {
 	return false
 }
 
+// HasReqOptSomewhere returns true if this command or one of its subcommands contains a
+// required option.
+func (c *command) HasReqOptSomewhere() bool {
+	for i := range c.Opts {
+		if c.Opts[i].IsRequired() {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasReqOptSomewhere() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasVariadicArgSomewhere returns true if this command or one of its subcommands has a
+// variadic (slice) argument.
+func (c *command) HasVariadicArgSomewhere() bool {
+	if n := len(c.Args); n > 0 && c.Args[n-1].IsVariadic() {
+		return true
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasVariadicArgSomewhere() {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsExtraArgs returns true if this command tolerates positional arguments beyond the
+// ones it declares, either via the 'clap:cmd_allow_extra_args' directive or by having a
+// 'clap:extra_args' field to hold them. With only the directive, they're discarded.
+func (c *command) AllowsExtraArgs() bool {
+	_, ok := c.Data.getConfig("cmd_allow_extra_args")
+	return ok || c.ExtraArgsField != ""
+}
+
+// AllowsExtraArgsSomewhere returns true if this command or one of its subcommands allows
+// extra positional arguments.
+func (c *command) AllowsExtraArgsSomewhere() bool {
+	if c.AllowsExtraArgs() {
+		return true
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].AllowsExtraArgsSomewhere() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasShortOptSomewhere returns true if this command or one of its subcommands contains an
+// option with a short name.
+func (c *command) HasShortOptSomewhere() bool {
+	for i := range c.Opts {
+		if c.Opts[i].Short != "" {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasShortOptSomewhere() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGlobalOptSomewhere returns true if this command or one of its subcommands contains a
+// global option.
+func (c *command) HasGlobalOptSomewhere() bool {
+	for i := range c.Opts {
+		if c.Opts[i].IsGlobal() {
+			return true
+		}
+	}
+	for i := range c.Subcmds {
+		if c.Subcmds[i].HasGlobalOptSomewhere() {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *command) HasNonHelpOpts() bool {
 	for i := range c.Opts {
 		if c.Opts[i].Name != "h" {
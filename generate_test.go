@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestGeneratedParser generates the parser for the command in testdata/parser with each
+// option style and runs that package's own tests, which check what it accepts as well as
+// each kind of error it reports.
+func TestGeneratedParser(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of generated code in short mode")
+	}
+	for _, style := range []string{"go", "gnu"} {
+		t.Run(style, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range []string{"go.mod", "main.go", "main_test.go"} {
+				b, err := os.ReadFile(filepath.Join("testdata", "parser", name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			styleFile := fmt.Sprintf("package main\n\nconst gnuStyle = %t\n", style == "gnu")
+			if err := os.WriteFile(filepath.Join(dir, "style_test.go"), []byte(styleFile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := gen(&goclap{
+				rootCmdType: "mycli",
+				srcDir:      dir,
+				outFilePath: filepath.Join(dir, "clap.gen.go"),
+				optStyle:    style,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command("go", "test", ".")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("go test with -style %s: %v\n%s", style, err, out)
+			}
+		})
+	}
+}
+
+func TestAssignImportNames(t *testing.T) {
+	root := command{Subcmds: []command{
+		{TypeName: "Get", pkgPath: "m/internal/cli", pkgName: "cli"},
+		{TypeName: "Put", pkgPath: "m/internal/cli", pkgName: "cli"},
+		{TypeName: "Rm", pkgPath: "m/other/cli", pkgName: "cli", Subcmds: []command{
+			{TypeName: "All", pkgPath: "m/internal/flag", pkgName: "flag"},
+		}},
+		{TypeName: "local"},
+	}}
+	specs := root.assignImportNames()
+	wantSpecs := []string{`"m/internal/cli"`, `cli2 "m/other/cli"`, `flag2 "m/internal/flag"`}
+	if !slices.Equal(specs, wantSpecs) {
+		t.Errorf("got import specs %q, want %q", specs, wantSpecs)
+	}
+	for _, tc := range []struct {
+		c        *command
+		recvType string
+		qualType string
+	}{
+		{c: &root.Subcmds[0], recvType: "clapCliGet", qualType: "cli.Get"},
+		{c: &root.Subcmds[1], recvType: "clapCliPut", qualType: "cli.Put"},
+		{c: &root.Subcmds[2], recvType: "clapCli2Rm", qualType: "cli2.Rm"},
+		{c: &root.Subcmds[2].Subcmds[0], recvType: "clapFlag2All", qualType: "flag2.All"},
+		{c: &root.Subcmds[3], recvType: "local", qualType: "local"},
+	} {
+		if got := tc.c.RecvType(); got != tc.recvType {
+			t.Errorf("%s: got receiver type %q, want %q", tc.c.TypeName, got, tc.recvType)
+		}
+		if got := tc.c.QualifiedType(); got != tc.qualType {
+			t.Errorf("%s: got qualified type %q, want %q", tc.c.TypeName, got, tc.qualType)
+		}
+	}
+}
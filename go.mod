@@ -1,5 +1,12 @@
 module github.com/steverusso/goclap
 
-go 1.22
+go 1.25.0
 
 retract v0.0.1-alpha // Incorrect module path
+
+require golang.org/x/tools v0.44.0
+
+require (
+	golang.org/x/mod v0.35.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+)
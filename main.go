@@ -1,8 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/token"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"runtime/debug"
 	"strings"
 	"time"
@@ -20,12 +25,21 @@ type goclap struct {
 	//
 	// clap:opt srcdir
 	srcDir string
+	// Comma separated build tags to consider when selecting which source files to parse
+	// (as with "go build -tags").
+	//
+	// clap:opt tags
+	buildTags string
 	// Include goclap's version info in the generated code.
 	//
 	// clap:opt with-version
 	withVersion bool
 	// Output file path (default "./clap.gen.go").
 	//
+	// The default is the shell's conventional script name when generating completions,
+	// "./man" (a directory) when generating man pages, "./<name>.md" when generating
+	// Markdown, and standard output when dumping JSON.
+	//
 	// clap:opt out
 	outFilePath string
 	// How the usage message for each command will be structured (possible values: packed
@@ -33,6 +47,46 @@ type goclap struct {
 	//
 	// clap:opt usg-layout-kind
 	usgLayoutKind string
+	// The style of options the generated code will parse (possible values: go or gnu).
+	//
+	// The "go" style mimics the standard library's flag package, and the "gnu" style
+	// allows for long and short names, bundled short options, and "--name=value".
+	//
+	// clap:opt style
+	optStyle string
+	// Write a completion script for the given shell instead of Go code.
+	//
+	// The script completes options, subcommands, the choices of options and arguments
+	// that have them, and file names for any other string options and arguments. It's
+	// meant for the "-style" that the Go code was generated with.
+	//
+	// clap:opt completions
+	// clap:choices bash,zsh,fish
+	completions string
+	// Write a man page for the root command and each subcommand into the output
+	// directory instead of Go code.
+	//
+	// clap:opt man
+	man bool
+	// Write a Markdown reference document for the root command and all subcommands
+	// instead of Go code.
+	//
+	// clap:opt markdown
+	markdown bool
+	// Write the parsed command model (including all clap directives and source positions)
+	// as JSON instead of Go code.
+	//
+	// clap:opt dump-json
+	dumpJSON bool
+	// Don't write anything, but exit with an error and print a diff if the output file
+	// isn't what would be generated.
+	//
+	// clap:opt check
+	check bool
+	// Treat warnings (such as skipped fields) as errors.
+	//
+	// clap:opt werror
+	werror bool
 	// Max width for lines of text in the usage message.
 	//
 	// clap:opt usg-text-width
@@ -47,6 +101,18 @@ type basicType string
 
 func (t basicType) IsBool() bool { return t == "bool" }
 
+// IsSlice returns true if this is a slice of a basic type (e.g. `[]string`).
+func (t basicType) IsSlice() bool { return strings.HasPrefix(string(t), "[]") }
+
+// IsPointer returns true if this is a pointer to a custom value type (e.g. `*flag.Value`).
+func (t basicType) IsPointer() bool { return strings.HasPrefix(string(t), "*") }
+
+// ElemType returns the element type of a slice or pointer type, or the type itself
+// otherwise.
+func (t basicType) ElemType() basicType {
+	return basicType(strings.TrimPrefix(strings.TrimPrefix(string(t), "[]"), "*"))
+}
+
 type buildVersionInfo struct {
 	modVersion      string
 	commitHash      string
@@ -119,6 +185,60 @@ func (d *clapData) getConfig(k string) (string, bool) {
 	return "", false
 }
 
+// choices returns the comma separated values of a 'clap:choices' directive (if any).
+func (d *clapData) choices() []string {
+	v, ok := d.getConfig("choices")
+	if !ok {
+		return nil
+	}
+	choices := strings.Split(v, ",")
+	for i := range choices {
+		choices[i] = strings.TrimSpace(choices[i])
+	}
+	return choices
+}
+
+// bounds returns the values of any 'clap:min' and 'clap:max' directives (either can be
+// empty).
+func (d *clapData) bounds() (string, string) {
+	minVal, _ := d.getConfig("min")
+	maxVal, _ := d.getConfig("max")
+	return minVal, maxVal
+}
+
+// boundsText returns the usage text describing any 'clap:min' or 'clap:max' directives
+// (e.g. "min: 1, max: 10").
+func (d *clapData) boundsText() string {
+	minVal, maxVal := d.bounds()
+	var parts []string
+	if minVal != "" {
+		parts = append(parts, "min: "+minVal)
+	}
+	if maxVal != "" {
+		parts = append(parts, "max: "+maxVal)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// valueNotes returns the notes about an option or argument's value (its choices, bounds,
+// default and env var), as they appear in the roomy usage layout but without brackets.
+func (d *clapData) valueNotes() []string {
+	var notes []string
+	if v := d.choices(); v != nil {
+		notes = append(notes, "possible values: "+strings.Join(v, ", "))
+	}
+	if v := d.boundsText(); v != "" {
+		notes = append(notes, v)
+	}
+	if v, ok := d.getConfig("default"); ok {
+		notes = append(notes, "default: "+v)
+	}
+	if v, ok := d.getConfig("env"); ok {
+		notes = append(notes, "env: "+v)
+	}
+	return notes
+}
+
 type command struct {
 	IsRoot      bool
 	parentNames []string
@@ -128,13 +248,32 @@ type command struct {
 	Opts        []option
 	Args        []argument
 	Subcmds     []command
+	pos         token.Position // Where the command's struct type is defined.
+
+	// pkgPath and pkgName are those of the package that the command's type is defined in
+	// if it's not the root command's package. The generated code can't declare methods on
+	// a type from another package, so it declares them on a local type with the same
+	// underlying struct instead (see RecvType).
+	pkgPath string
+	pkgName string
+	// importName is what the generated code imports pkgPath as.
+	importName string
+
+	// InheritedOpts are the global options of this command's ancestors.
+	InheritedOpts []option
+
+	// ExtraArgsField is the name of the `[]string` field (if any) that will hold any
+	// positional arguments remaining after all of the command's arguments are parsed.
+	ExtraArgsField string
 }
 
 type option struct {
 	FieldType basicType
 	FieldName string
 	Name      string
+	Short     string
 	data      clapData
+	pos       token.Position
 }
 
 type argument struct {
@@ -142,6 +281,7 @@ type argument struct {
 	FieldName string
 	name      string
 	data      clapData
+	pos       token.Position
 }
 
 func (c *command) UsgName() string {
@@ -163,33 +303,156 @@ func gen(c *goclap) error {
 		os.Exit(1)
 	}
 
-	rootCmd, pkgName, err := parse(c.srcDir, rootCmdTypeName)
+	switch c.optStyle {
+	case "":
+		c.optStyle = "go"
+	case "go", "gnu":
+	default:
+		return fmt.Errorf("unknown option style '%s' (possible values: go or gnu)", c.optStyle)
+	}
+
+	rootCmd, pkgName, err := parse(c.srcDir, rootCmdTypeName, c.buildTags)
 	if err != nil {
 		return err
 	}
+	if c.werror && numWarnings > 0 {
+		return fmt.Errorf("%d warning(s) treated as errors", numWarnings)
+	}
 
-	code, err := generate(c.withVersion, pkgName, c.usgTextWidth, c.usgLayoutKind, &rootCmd)
+	var numModes int
+	for _, on := range []bool{c.completions != "", c.man, c.markdown, c.dumpJSON} {
+		if on {
+			numModes++
+		}
+	}
+	if numModes > 1 {
+		return fmt.Errorf("only one of '-completions', '-man', '-markdown' and '-dump-json' can be used at a time")
+	}
+
+	files, err := c.outputFiles(pkgName, &rootCmd)
 	if err != nil {
 		return err
 	}
+	if c.check {
+		return checkOutputFiles(files)
+	}
+	for _, f := range files {
+		if err := writeOutput(f.name, f.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputFiles returns the files that the selected output mode produces, each named by its
+// full path. A file with an empty name is meant for standard output.
+func (c *goclap) outputFiles(pkgName string, rootCmd *command) ([]outputFile, error) {
+	if c.dumpJSON {
+		b, err := dumpJSON(rootCmd)
+		if err != nil {
+			return nil, fmt.Errorf("encoding JSON: %w", err)
+		}
+		return []outputFile{{name: c.outFilePath, data: b}}, nil
+	}
 
+	if c.markdown {
+		if c.outFilePath == "" {
+			c.outFilePath = "./" + rootCmd.UsgName() + ".md"
+		}
+		return []outputFile{{name: c.outFilePath, data: genMarkdown(c.optStyle == "gnu", rootCmd)}}, nil
+	}
+
+	if c.man {
+		if c.outFilePath == "" {
+			c.outFilePath = "./man"
+		}
+		pages := genManPages(c.optStyle == "gnu", rootCmd)
+		for i := range pages {
+			pages[i].name = filepath.Join(c.outFilePath, pages[i].name)
+		}
+		return pages, nil
+	}
+
+	if c.completions != "" {
+		script, err := genCompletions(c.completions, c.optStyle == "gnu", rootCmd)
+		if err != nil {
+			return nil, err
+		}
+		if c.outFilePath == "" {
+			c.outFilePath = defaultCompletionsPath(c.completions, rootCmd.UsgName())
+		}
+		return []outputFile{{name: c.outFilePath, data: script}}, nil
+	}
+
+	code, err := generate(c.withVersion, pkgName, c.usgTextWidth, c.usgLayoutKind, c.optStyle, rootCmd)
+	if err != nil {
+		return nil, err
+	}
 	if c.outFilePath == "" {
 		c.outFilePath = "./clap.gen.go"
 	}
-	f, err := os.OpenFile(c.outFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	return []outputFile{{name: c.outFilePath, data: code}}, nil
+}
+
+// checkOutputFiles compares the given files to what's currently on disk without writing
+// anything. It prints a unified diff for each file that differs (a missing file counts as
+// empty) and returns an error if there are any.
+func checkOutputFiles(files []outputFile) error {
+	var stale []string
+	for _, f := range files {
+		if f.name == "" {
+			return fmt.Errorf("'-check' needs an '-out' file to compare against")
+		}
+		existing, err := os.ReadFile(f.name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("reading '%s': %w", f.name, err)
+		}
+		if bytes.Equal(existing, f.data) {
+			continue
+		}
+		fmt.Print(unifiedDiff(f.name, existing, f.data))
+		stale = append(stale, "'"+f.name+"'")
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("out of date: %s", strings.Join(stale, ", "))
+	}
+	return nil
+}
+
+// writeOutput writes the data to the file at the given path (creating its parent
+// directory if needed) unless it already has that exact content, or to standard output
+// if the path is empty.
+func writeOutput(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	// Leave the file (and its modification time) alone if nothing changed.
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
 	if err != nil {
-		return fmt.Errorf("opening '%s': %w", c.outFilePath, err)
+		return fmt.Errorf("opening '%s': %w", path, err)
 	}
 	defer f.Close()
 
-	if _, err = f.Write(code); err != nil {
-		return fmt.Errorf("writing code to output file: %w", err)
+	if _, err = f.Write(data); err != nil {
+		return fmt.Errorf("writing to output file: %w", err)
 	}
 
 	return nil
 }
 
+// numWarnings is the number of warnings printed so far.
+var numWarnings int
+
 func warn(format string, a ...any) {
+	numWarnings++
 	fmt.Fprintf(os.Stderr, "\033[1;33mwarning:\033[0m "+format+"\n", a...)
 }
 
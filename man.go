@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// outputFile is a file to be written by one of goclap's non-code output modes.
+type outputFile struct {
+	name string
+	data []byte
+}
+
+// genManPages returns a roff man page (in section 1) for the given command and one for
+// each of its subcommands, named after the full command path (e.g. "mycli-subcmd.1").
+func genManPages(gnuStyle bool, root *command) []outputFile {
+	var pages []outputFile
+	root.walk(func(c *command) {
+		pages = append(pages, outputFile{
+			name: c.pageName() + ".1",
+			data: []byte(manPage(gnuStyle, c)),
+		})
+	})
+	return pages
+}
+
+// pageName returns the name of this command's documentation page, which is its full path
+// joined by dashes (e.g. "mycli-subcmd").
+func (c *command) pageName() string { return strings.ReplaceAll(c.path(), " ", "-") }
+
+// roffEscape escapes the given text so that it's displayed as is within a man page.
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		// Lines starting with these would otherwise be control lines.
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = `\&` + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func manPage(gnuStyle bool, c *command) string {
+	var b strings.Builder
+	b.WriteString(".\\\" generated by goclap; DO NOT EDIT\n")
+	fmt.Fprintf(&b, ".TH \"%s\" 1\n", roffEscape(strings.ToUpper(c.pageName())))
+
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(c.pageName()), roffEscape(plainText(c.Data.Blurb)))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	b.WriteString(".nf\n")
+	for _, u := range c.UsageLines(gnuStyle) {
+		fmt.Fprintf(&b, "%s\n", roffEscape(c.Parents()+u))
+	}
+	b.WriteString(".fi\n")
+
+	if len(c.Data.overview) > 0 {
+		b.WriteString(".SH DESCRIPTION\n")
+		for i, p := range c.Data.overview {
+			if i > 0 {
+				b.WriteString(".PP\n")
+			}
+			b.WriteString(roffEscape(strings.TrimRight(plainText(p), "\n")) + "\n")
+		}
+	}
+
+	writeManOpts(&b, "OPTIONS", gnuStyle, c.Opts)
+	writeManOpts(&b, "GLOBAL OPTIONS", gnuStyle, c.InheritedOpts)
+
+	if len(c.Args) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for i := range c.Args {
+			a := &c.Args[i]
+			b.WriteString(".TP\n")
+			fmt.Fprintf(&b, "\\fI%s\\fR\n", roffEscape(a.UsgName()))
+			writeManDesc(&b, &a.data)
+		}
+	}
+
+	if c.HasSubcmds() {
+		b.WriteString(".SH COMMANDS\n")
+		for i := range c.Subcmds {
+			sc := &c.Subcmds[i]
+			b.WriteString(".TP\n")
+			fmt.Fprintf(&b, "\\fB%s\\fR\n", roffEscape(strings.Join(sc.allNames(), ", ")))
+			fmt.Fprintf(&b, "%s (see \\fB%s\\fR(1))\n", roffEscape(plainText(sc.Data.Blurb)), roffEscape(sc.pageName()))
+		}
+	}
+
+	// Refer to the parent command's page and to each subcommand's page.
+	var related []string
+	if !c.IsRoot {
+		related = append(related, strings.Join(c.parentNames, "-"))
+	}
+	for i := range c.Subcmds {
+		related = append(related, c.Subcmds[i].pageName())
+	}
+	if len(related) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		for i, r := range related {
+			sep := ""
+			if i < len(related)-1 {
+				sep = " ,"
+			}
+			fmt.Fprintf(&b, ".BR %s (1)%s\n", roffEscape(r), sep)
+		}
+	}
+	return b.String()
+}
+
+func writeManOpts(b *strings.Builder, heading string, gnuStyle bool, opts []option) {
+	if len(opts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, ".SH %s\n", heading)
+	for i := range opts {
+		o := &opts[i]
+		names := strings.Split(o.usgNames(gnuStyle), ", ")
+		for j := range names {
+			names[j] = "\\fB" + roffEscape(names[j]) + "\\fR"
+		}
+		b.WriteString(".TP\n")
+		b.WriteString(strings.Join(names, ", "))
+		if an := o.usgArgName(); an != "" {
+			b.WriteString(" \\fI" + roffEscape(an) + "\\fR")
+		}
+		b.WriteString("\n")
+		writeManDesc(b, &o.data)
+	}
+}
+
+// writeManDesc writes the description of an option or argument, followed by any notes
+// about its value on separate lines.
+func writeManDesc(b *strings.Builder, data *clapData) {
+	b.WriteString(roffEscape(plainText(data.Blurb)) + "\n")
+	for _, n := range data.valueNotes() {
+		b.WriteString(".br\n")
+		b.WriteString("[" + roffEscape(n) + "]\n")
+	}
+}
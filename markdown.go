@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// genMarkdown returns a Markdown reference document with a section for the given command
+// and each of its subcommands. Each section has the command's blurb, overview, usage
+// lines, options, arguments and links to the sections of its subcommands.
+func genMarkdown(gnuStyle bool, root *command) []byte {
+	var b strings.Builder
+	b.WriteString("<!-- generated by goclap; DO NOT EDIT -->\n")
+	root.walk(func(c *command) {
+		heading := "##"
+		if c.IsRoot {
+			heading = "#"
+		}
+		fmt.Fprintf(&b, "\n%s %s\n\n", heading, c.path())
+		fmt.Fprintf(&b, "%s\n", plainText(c.Data.Blurb))
+		for _, p := range c.Data.overview {
+			fmt.Fprintf(&b, "\n%s", plainText(p))
+		}
+
+		b.WriteString("\n```\n")
+		for _, u := range c.UsageLines(gnuStyle) {
+			fmt.Fprintf(&b, "%s\n", c.Parents()+u)
+		}
+		b.WriteString("```\n")
+
+		writeMarkdownOpts(&b, "Options", gnuStyle, c.Opts)
+		writeMarkdownOpts(&b, "Global options", gnuStyle, c.InheritedOpts)
+
+		if len(c.Args) > 0 {
+			b.WriteString("\n**Arguments**\n\n")
+			for i := range c.Args {
+				a := &c.Args[i]
+				fmt.Fprintf(&b, "- `%s`: %s\n", a.UsgName(), markdownDesc(&a.data))
+			}
+		}
+
+		if c.HasSubcmds() {
+			b.WriteString("\n**Subcommands**\n\n")
+			for i := range c.Subcmds {
+				sc := &c.Subcmds[i]
+				names := sc.allNames()
+				for j := range names {
+					names[j] = "`" + names[j] + "`"
+				}
+				fmt.Fprintf(&b, "- [%s](#%s): %s\n", strings.Join(names, ", "), strings.ToLower(sc.pageName()), plainText(sc.Data.Blurb))
+			}
+		}
+	})
+	return []byte(b.String())
+}
+
+func writeMarkdownOpts(b *strings.Builder, heading string, gnuStyle bool, opts []option) {
+	if len(opts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n**%s**\n\n", heading)
+	for i := range opts {
+		o := &opts[i]
+		names := strings.Split(o.usgNames(gnuStyle), ", ")
+		if an := o.usgArgName(); an != "" {
+			names[len(names)-1] += " " + an
+		}
+		for j := range names {
+			names[j] = "`" + names[j] + "`"
+		}
+		fmt.Fprintf(b, "- %s: %s\n", strings.Join(names, ", "), markdownDesc(&o.data))
+	}
+}
+
+// markdownDesc returns the description of an option or argument followed by any notes
+// about its value in parentheses.
+func markdownDesc(data *clapData) string {
+	s := plainText(data.Blurb)
+	if notes := data.valueNotes(); len(notes) > 0 {
+		s += " (" + strings.Join(notes, "; ") + ")"
+	}
+	return s
+}
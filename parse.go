@@ -4,12 +4,18 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
-	"go/parser"
 	"go/token"
+	"go/types"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
 )
 
 // backtickRepl describes how groups of backticks are replaced within usage message
@@ -22,6 +28,15 @@ const backtickRepl = "` + \"$0\" + `"
 // One or more backticks.
 var backtickRE = regexp.MustCompile("`+")
 
+// escapedBacktickRE matches groups of backticks that have been replaced by backtickRepl.
+var escapedBacktickRE = regexp.MustCompile("` \\+ \"(`+)\" \\+ `")
+
+// plainText undoes the backtick replacements made within the given usage message string
+// so that it can be used outside of generated Go code (such as in a man page).
+func plainText(s string) string {
+	return escapedBacktickRE.ReplaceAllString(s, "$1")
+}
+
 // helpOption is the default help option that is automatically added to any command's
 // options.
 var helpOption = option{
@@ -30,7 +45,7 @@ var helpOption = option{
 	data:      clapData{Blurb: "Show this help message"},
 }
 
-func parse(srcDir, rootCmdTypeName string) (command, string, error) {
+func parse(srcDir, rootCmdTypeName, buildTags string) (command, string, error) {
 	if srcDir == "" {
 		srcDir = "."
 	}
@@ -41,123 +56,436 @@ func parse(srcDir, rootCmdTypeName string) (command, string, error) {
 	}
 	rootCmdName := filepath.Base(absSrcDir)
 
-	entries, err := os.ReadDir(srcDir)
+	targetPkg, err := loadPackages(absSrcDir, buildTags)
 	if err != nil {
-		return command{}, "", fmt.Errorf("reading src dir: %w", err)
+		return command{}, "", err
 	}
-
-	fset := token.NewFileSet() // positions are relative to fset
-	astFiles := make([]*ast.File, 0, len(entries))
-	for _, de := range entries {
-		if de.IsDir() || !strings.HasSuffix(de.Name(), ".go") || strings.HasPrefix(de.Name(), ".") {
-			continue
-		}
-		fpath := filepath.Join(srcDir, de.Name())
-		fileNode, err := parser.ParseFile(fset, fpath, nil, parser.ParseComments)
-		if err != nil {
-			return command{}, "", fmt.Errorf("parsing source file '%s': %w", fpath, err)
+	fset := targetPkg.fset
+	if decls := targetPkg.typeDecls(rootCmdTypeName); len(decls) > 1 {
+		positions := make([]string, len(decls))
+		for i := range decls {
+			positions[i] = fset.Position(decls[i].Pos()).String()
 		}
-		astFiles = append(astFiles, fileNode)
+		return command{}, "", fmt.Errorf("type '%s' is declared more than once: %s", rootCmdTypeName, strings.Join(positions, ", "))
 	}
-
-	targetPkg := parsedPackage{files: astFiles}
-	rootStrct := findStruct(&targetPkg, rootCmdTypeName)
+	rootStrct := findStruct(targetPkg, rootCmdTypeName)
 	if rootStrct == nil {
-		return command{}, "", fmt.Errorf("could not find a struct type named '%s'", rootCmdTypeName)
+		return command{}, "", fmt.Errorf("could not find a struct type named '%s' in '%s'", rootCmdTypeName, absSrcDir)
 	}
 
-	data := getCmdClapData(&targetPkg, rootCmdTypeName)
+	data := getCmdClapData(targetPkg, rootCmdTypeName)
+	if err = checkDirectives(&data, onCommand); err != nil {
+		return command{}, "", fmt.Errorf("%s: '%s': %w", fset.Position(rootStrct.Pos()), rootCmdTypeName, err)
+	}
 	if data.Blurb == "" {
-		warn("no root command description provided")
+		targetPkg.warnAt(rootStrct.Pos(), "no root command description provided")
 	}
 	root := command{
 		IsRoot:    true,
 		TypeName:  rootCmdTypeName,
 		FieldName: rootCmdName,
 		Data:      data,
+		pos:       fset.Position(rootStrct.Pos()),
 	}
 
-	if err = addChildren(&targetPkg, &root, rootStrct); err != nil {
+	if err = addChildren(targetPkg, targetPkg, &root, rootStrct); err != nil {
+		return command{}, "", err
+	}
+	if err = root.inheritGlobalOpts(nil); err != nil {
 		return command{}, "", err
 	}
 	return root, targetPkg.files[0].Name.Name, nil
 }
 
 type parsedPackage struct {
+	fset  *token.FileSet
 	files []*ast.File
+	info  *types.Info
+	types *types.Package
+
+	// module holds each loaded package within the same module (including this one) by
+	// its import path.
+	module map[string]*parsedPackage
+}
+
+// loadPackages loads the package in the given directory along with every package within
+// the same module that it (directly or indirectly) imports, so that the types of fields
+// can be followed into them (for example, an option group embedded from an
+// `internal/cli` package). Files are selected just as `go build` would select them with the
+// given build tags, so test files and files excluded by build constraints are left out.
+func loadPackages(dir, buildTags string) (*parsedPackage, error) {
+	cfg := packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps | packages.NeedModule |
+			packages.NeedExportFile,
+		Dir: dir,
+	}
+	if buildTags != "" {
+		cfg.BuildFlags = []string{"-tags=" + buildTags}
+	}
+	// The first pass only lists the package and its dependencies in order to find which
+	// of them are within the same module.
+	listed, err := packages.Load(&cfg, ".")
+	if err != nil {
+		return nil, fmt.Errorf("loading package in '%s': %w", dir, err)
+	}
+	if len(listed) != 1 {
+		return nil, fmt.Errorf("expected one package in '%s', found %d", dir, len(listed))
+	}
+	rootPath, rootMod := listed[0].PkgPath, listed[0].Module
+	inModule := make(map[string]bool)
+	var exportPkg *packages.Package // Any package outside the module.
+	packages.Visit(listed, nil, func(p *packages.Package) {
+		if p.PkgPath == rootPath || (p.Module != nil && rootMod != nil && p.Module.Path == rootMod.Path) {
+			inModule[p.PkgPath] = true
+		} else if exportPkg == nil && p.ExportFile != "" {
+			exportPkg = p
+		}
+	})
+
+	// Only the packages within the module are parsed and type checked from source. Their
+	// other imports (such as the standard library) come from export data, which is much
+	// faster than type checking them from source as well. However, export data can only be
+	// read if its format is known to the x/tools version that goclap was built with, so
+	// everything is type checked from source if it can't be. Type errors are ignored
+	// because they are likely irrelevant to this tool (for example, the previously
+	// generated code might be out of date). The type info is still populated for
+	// everything that was understood.
+	fset := token.NewFileSet() // positions are relative to fset
+	cfg.Fset = fset
+	cfg.Mode = packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedSyntax |
+		packages.NeedTypes | packages.NeedTypesInfo
+	patterns := slices.Sorted(maps.Keys(inModule))
+	if err := checkExportData(exportPkg); err != nil {
+		warn("type checking all dependencies from source, which is slower, because the Go toolchain's export data can't be read (%v)", err)
+		cfg.Mode |= packages.NeedDeps
+		patterns = []string{"."}
+	}
+	loaded, err := packages.Load(&cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("loading package in '%s': %w", dir, err)
+	}
+
+	module := make(map[string]*parsedPackage)
+	var root *packages.Package
+	packages.Visit(loaded, nil, func(p *packages.Package) {
+		if !inModule[p.PkgPath] {
+			return
+		}
+		if p.PkgPath == rootPath {
+			root = p
+		}
+		module[p.PkgPath] = &parsedPackage{
+			fset:   fset,
+			files:  p.Syntax,
+			info:   p.TypesInfo,
+			types:  p.Types,
+			module: module,
+		}
+	})
+	if root == nil {
+		return nil, fmt.Errorf("package '%s' is missing from those loaded in '%s'", rootPath, dir)
+	}
+	for _, e := range root.Errors {
+		if e.Kind == packages.ParseError {
+			return nil, fmt.Errorf("parsing source file: %w", e)
+		}
+	}
+	if len(root.Syntax) == 0 {
+		if len(root.Errors) > 0 {
+			return nil, fmt.Errorf("loading package in '%s': %w", dir, root.Errors[0])
+		}
+		return nil, fmt.Errorf("no Go source files in '%s'", dir)
+	}
+	return module[rootPath], nil
+}
+
+// checkExportData returns an error if the export data of the given package (as produced by
+// the installed Go toolchain) can't be read. A nil package means that there wasn't any to
+// check.
+func checkExportData(pkg *packages.Package) error {
+	if pkg == nil {
+		return nil
+	}
+	f, err := os.Open(pkg.ExportFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := gcexportdata.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("reading export data: %w", err)
+	}
+	if _, err := gcexportdata.Read(r, token.NewFileSet(), make(map[string]*types.Package), pkg.PkgPath); err != nil {
+		return fmt.Errorf("reading export data: %w", err)
+	}
+	return nil
+}
+
+// warnAt prints a warning prefixed with the "file:line:col" of the given position.
+func (pkg *parsedPackage) warnAt(pos token.Pos, format string, a ...any) {
+	warn("%s: "+format, append([]any{pkg.fset.Position(pos)}, a...)...)
+}
+
+var (
+	errorType = types.Universe.Lookup("error").Type()
+
+	// flagValueIface is the `flag.Value` interface.
+	flagValueIface = newInterface(
+		newMethod("String", nil, types.Typ[types.String]),
+		newMethod("Set", types.Typ[types.String], errorType),
+	)
+
+	// textUnmarshalerIface is the `encoding.TextUnmarshaler` interface.
+	textUnmarshalerIface = newInterface(
+		newMethod("UnmarshalText", types.NewSlice(types.Typ[types.Byte]), errorType),
+	)
+
+	// binaryUnmarshalerIface is the `encoding.BinaryUnmarshaler` interface.
+	binaryUnmarshalerIface = newInterface(
+		newMethod("UnmarshalBinary", types.NewSlice(types.Typ[types.Byte]), errorType),
+	)
+)
+
+func newInterface(methods ...*types.Func) *types.Interface {
+	return types.NewInterfaceType(methods, nil).Complete()
+}
+
+// newMethod returns an interface method with at most one parameter and one result.
+func newMethod(name string, param, result types.Type) *types.Func {
+	var params, results []*types.Var
+	if param != nil {
+		params = append(params, types.NewParam(token.NoPos, nil, "", param))
+	}
+	if result != nil {
+		results = append(results, types.NewParam(token.NoPos, nil, "", result))
+	}
+	sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), types.NewTuple(results...), false)
+	return types.NewFunc(token.NoPos, nil, name, sig)
+}
+
+// customValueType returns the type of the field with the given name if that type (or a
+// slice of it or a pointer to it) implements `flag.Value`, `encoding.TextUnmarshaler` or
+// `encoding.BinaryUnmarshaler` (such as `url.URL`) through a pointer receiver. The
+// returned type will be the name of the first of those interfaces that it implements
+// (possibly prefixed with "[]" or "*") because that's all the generated code needs to
+// know. An empty string is returned if the field's type doesn't qualify or is already
+// otherwise supported (such as `time.Time`).
+func (pkg *parsedPackage) customValueType(name *ast.Ident) basicType {
+	obj := pkg.info.Defs[name]
+	if obj == nil {
+		return ""
+	}
+	t := obj.Type()
+	prefix := ""
+	if s, ok := t.(*types.Slice); ok {
+		t = s.Elem()
+		prefix = "[]"
+	}
+	if p, ok := t.(*types.Pointer); ok {
+		if prefix != "" {
+			return "" // Slices of pointers aren't supported.
+		}
+		t = p.Elem()
+		prefix = "*"
+	}
+	if supportedType(t) != "" {
+		return ""
+	}
+	ptr := types.NewPointer(t)
+	switch {
+	case types.Implements(ptr, flagValueIface):
+		return basicType(prefix + "flag.Value")
+	case types.Implements(ptr, textUnmarshalerIface):
+		return basicType(prefix + "encoding.TextUnmarshaler")
+	case types.Implements(ptr, binaryUnmarshalerIface):
+		return basicType(prefix + "encoding.BinaryUnmarshaler")
+	}
+	return ""
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// namedBasicType returns the underlying basic type of the field with the given name if
+// the field's type (or the element type of a slice) is a named type such as `type Port
+// uint16`. The generated code works with the named type directly, so only its underlying
+// kind is needed. An empty string is returned if the field's type doesn't qualify.
+func (pkg *parsedPackage) namedBasicType(name *ast.Ident) basicType {
+	obj := pkg.info.Defs[name]
+	if obj == nil {
+		return ""
+	}
+	t := obj.Type()
+	prefix := ""
+	if s, ok := t.(*types.Slice); ok {
+		t = s.Elem()
+		prefix = "[]"
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	if supportedType(named) != "" {
+		return "" // It's already supported directly (e.g. `time.Duration`).
+	}
+	b, ok := named.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	kind := basicTypeFromName(b.Name())
+	if kind == "" {
+		return ""
+	}
+	return basicType(prefix) + kind
+}
+
+// addChildren adds the options, arguments and subcommands for the fields of the command's
+// struct, which is declared in the `from` package.
+func addChildren(pkg, from *parsedPackage, c *command, strct *ast.StructType) error {
+	if err := addFields(pkg, from, c, strct, ""); err != nil {
+		return err
+	}
+	// A slice argument takes all the remaining args, which would leave none for a subcommand.
+	if n := len(c.Args); n > 0 && c.Args[n-1].IsVariadic() && c.HasSubcmds() {
+		a := &c.Args[n-1]
+		return fmt.Errorf("%s: '%s.%s': commands with subcommands can't have a slice argument", a.pos, c.TypeName, a.FieldName)
+	}
+	c.Opts = append(c.Opts, helpOption)
+	return nil
 }
 
-func addChildren(pkg *parsedPackage, c *command, strct *ast.StructType) error {
-	// Read in the struct fields.
+// addFields adds the options, arguments and subcommands for the fields of the given
+// struct to the command. The struct is declared in the `from` package, which is only
+// different from the command's package for embedded structs from other packages. The path
+// is that of any embedded structs (e.g. "connOpts.") through which the fields are reached
+// from the command's struct.
+func addFields(pkg, from *parsedPackage, c *command, strct *ast.StructType, path string) error {
 	for _, field := range strct.Fields.List {
 		if len(field.Names) == 0 {
-			if selExpr, ok := field.Type.(*ast.SelectorExpr); ok {
-				warn("skipping embedded field `%s.%s`", c.TypeName, selExpr.Sel.Name)
-			} else {
-				warn("skipping embedded field in `%s`", c.TypeName)
+			if err := addEmbeddedFields(pkg, from, c, field, path); err != nil {
+				return err
 			}
 			continue
 		}
 		if len(field.Names) > 1 {
-			warn("skipping multi named field %s", field.Names)
+			pkg.warnAt(field.Pos(), "skipping multi named field %s", field.Names)
 			continue
 		}
 		fieldName := field.Names[0].Name
-		typeAndField := fmt.Sprintf("'%s.%s'", c.TypeName, fieldName)
+		fieldPath := path + fieldName
+		fieldPos := pkg.fset.Position(field.Pos())
+		// Errors about the field are prefixed with its position as well as its name.
+		typeAndField := fmt.Sprintf("%s: '%s.%s'", fieldPos, c.TypeName, fieldPath)
+		if from != pkg && !ast.IsExported(fieldName) {
+			pkg.warnAt(field.Pos(), "skipping '%s.%s': fields from other packages must be exported", c.TypeName, fieldPath)
+			continue
+		}
 		if _, ok := field.Type.(*ast.StructType); ok {
-			warn("skipping %s (commands must be struct pointers)", typeAndField)
+			pkg.warnAt(field.Pos(), "skipping '%s.%s' (commands must be struct pointers)", c.TypeName, fieldPath)
 			continue
 		}
-		if star, ok := field.Type.(*ast.StarExpr); ok {
-			idnt, ok := star.X.(*ast.Ident)
-			if !ok {
-				warn("skipping %s: non-struct pointers are unsupported", typeAndField)
+		// A field with a type that implements `flag.Value` or one of the unmarshaler
+		// interfaces is an option or argument, even if it's a pointer to a struct.
+		fieldType := from.customValueType(field.Names[0])
+		if fieldType == "" {
+			fieldType = from.namedBasicType(field.Names[0])
+		}
+		if star, ok := field.Type.(*ast.StarExpr); ok && fieldType == "" {
+			// The field will be a command if it points to a struct type defined within
+			// this module.
+			declPkg, subStrct := from.structOf(star.X)
+			if subStrct == nil {
+				if t := from.info.TypeOf(star.X); t != nil && isStruct(t) {
+					pkg.warnAt(field.Pos(), "skipping '%s.%s': type '%s' implements none of flag.Value, encoding.TextUnmarshaler or encoding.BinaryUnmarshaler", c.TypeName, fieldPath, t)
+				} else {
+					pkg.warnAt(field.Pos(), "skipping '%s.%s': non-struct pointers are unsupported", c.TypeName, fieldPath)
+				}
 				continue
 			}
-			// The field, which is of type `*IDENT,` will be a command if `IDENT`
-			// identifies a struct defined within this package.
-			subStrct := findStruct(pkg, idnt.Name)
-			if subStrct == nil {
-				warn("skipping %s: if type '%s' is defined, it's not a struct", typeAndField, idnt.Name)
+			typeName := typeIdent(star.X).Name
+			if declPkg != pkg && !ast.IsExported(typeName) {
+				pkg.warnAt(field.Pos(), "skipping '%s.%s': subcommand types from other packages must be exported", c.TypeName, fieldPath)
+				continue
+			}
+			if path != "" {
+				pkg.warnAt(field.Pos(), "skipping '%s.%s': subcommands can't be in embedded structs", c.TypeName, fieldPath)
 				continue
 			}
 			// The field is firmly considered a subcommand at this point.
+			if fieldDocs := parseComments(field.Doc); len(fieldDocs.configs) > 0 {
+				return fmt.Errorf("%s: directives for a subcommand go on the doc comment of type '%s'", typeAndField, typeName)
+			}
 			subcmd := command{
 				parentNames: append(c.parentNames, c.UsgName()),
-				TypeName:    idnt.Name,
+				TypeName:    typeName,
 				FieldName:   fieldName,
-				Data:        getCmdClapData(pkg, idnt.Name),
+				Data:        getCmdClapData(declPkg, typeName),
+				pos:         pkg.fset.Position(subStrct.Pos()),
+			}
+			if declPkg != pkg {
+				subcmd.pkgPath = declPkg.types.Path()
+				subcmd.pkgName = declPkg.types.Name()
+			}
+			if err := checkDirectives(&subcmd.Data, onCommand); err != nil {
+				return fmt.Errorf("%s: '%s': %w", subcmd.pos, typeName, err)
 			}
 			// Recursively build this subcommand from it's own struct type definition.
-			err := addChildren(pkg, &subcmd, subStrct)
+			err := addChildren(pkg, declPkg, &subcmd, subStrct)
 			if err != nil {
 				return err
 			}
 			c.Subcmds = append(c.Subcmds, subcmd)
 			continue
 		}
-		// From now on, it's either an option or an argument which can only be basic types
-		// (and those start out as identifiers).
-		idnt, ok := field.Type.(*ast.Ident)
-		if !ok {
-			warn("skipping %s (looking for ident, unsure how to handle %T)", typeAndField, field.Type)
-			continue
-		}
-		fieldType := basicTypeFromName(idnt.Name)
+		// From now on, it's either an option or an argument. Unless it's a custom value type
+		// or a named basic type, it can only be a supported type or a slice of one.
 		if fieldType == "" {
-			warn("skipping %s: unsupported option or argument type '%s'", typeAndField, idnt.Name)
-			continue
+			typ := types.Unalias(from.info.TypeOf(field.Type))
+			isSlice := false
+			if s, ok := typ.(*types.Slice); ok {
+				typ = types.Unalias(s.Elem())
+				isSlice = true
+			}
+			fieldType = supportedType(typ)
+			if fieldType == "" {
+				pkg.warnAt(field.Pos(), "skipping '%s.%s': unsupported option or argument type '%s'", c.TypeName, fieldPath, types.TypeString(typ, (*types.Package).Name))
+				continue
+			}
+			if isSlice {
+				fieldType = "[]" + fieldType
+			}
 		}
 		fieldDocs := parseComments(field.Doc)
+		if err := checkDirectives(&fieldDocs, onField); err != nil {
+			return fmt.Errorf("%s: %w", typeAndField, err)
+		}
 		cfgTypes := scanConfigTypes(fieldDocs.configs)
-		if cfgTypes.opts {
-			if cfgTypes.args {
-				return fmt.Errorf("%s has both option and argument configurations", typeAndField)
+		if cfgTypes.opts && cfgTypes.args {
+			return fmt.Errorf("%s: has both option and argument configurations", typeAndField)
+		}
+		if err := pkg.checkCompleteFunc(&fieldDocs); err != nil {
+			return fmt.Errorf("%s: %w", typeAndField, err)
+		}
+		if err := checkCompleteFiles(&fieldDocs, fieldType); err != nil {
+			return fmt.Errorf("%s: %w", typeAndField, err)
+		}
+		if _, ok := fieldDocs.getConfig("extra_args"); ok {
+			if fieldType != "[]string" {
+				return fmt.Errorf("%s: the 'clap:extra_args' field must be type []string", typeAndField)
+			}
+			if c.ExtraArgsField != "" {
+				return fmt.Errorf("%s: '%s' already has a 'clap:extra_args' field", typeAndField, c.TypeName)
 			}
+			c.ExtraArgsField = fieldPath
+			continue
+		}
+		if cfgTypes.opts {
 			// The field is firmly considered an option at this point.
-			err := c.addOption(fieldDocs, fieldName, fieldType)
+			err := c.addOption(fieldDocs, fieldPath, fieldType, fieldPos)
 			if err != nil {
-				return fmt.Errorf("parsing %s field as option: %w", typeAndField, err)
+				return fmt.Errorf("%s: parsing field as option: %w", typeAndField, err)
 			}
 			continue
 		}
@@ -165,14 +493,95 @@ func addChildren(pkg *parsedPackage, c *command, strct *ast.StructType) error {
 		if fieldType.IsBool() {
 			return fmt.Errorf("%s: arguments cannot be type bool", typeAndField)
 		}
+		if n := len(c.Args); n > 0 && c.Args[n-1].IsVariadic() {
+			return fmt.Errorf("%s: only the last argument can be a slice", typeAndField)
+		}
+		if _, ok := fieldDocs.getConfig("arg_time_layout"); ok && fieldType.ElemType() != "time.Time" {
+			return fmt.Errorf("%s: 'clap:arg_time_layout' is only valid on time.Time arguments", typeAndField)
+		}
+		if err := checkChoices(&fieldDocs, fieldType); err != nil {
+			return fmt.Errorf("%s: %w", typeAndField, err)
+		}
+		if err := checkBounds(&fieldDocs, fieldType); err != nil {
+			return fmt.Errorf("%s: %w", typeAndField, err)
+		}
 		c.Args = append(c.Args, argument{
 			data:      fieldDocs,
 			FieldType: fieldType,
-			FieldName: fieldName,
+			FieldName: fieldPath,
 			name:      strings.ToLower(fieldName),
+			pos:       fieldPos,
 		})
 	}
-	c.Opts = append(c.Opts, helpOption)
+	return nil
+}
+
+// addEmbeddedFields flattens the fields of an embedded struct into the command so that a
+// group of options and arguments can be shared by several commands. The struct can be
+// defined in any package within the module (but it can't be embedded by pointer).
+func addEmbeddedFields(pkg, from *parsedPackage, c *command, field *ast.Field, path string) error {
+	var name string
+	switch t := field.Type.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		name = t.Sel.Name
+	case *ast.StarExpr:
+		pkg.warnAt(field.Pos(), "skipping embedded field in `%s`: embedded pointers are unsupported", c.TypeName)
+		return nil
+	default:
+		pkg.warnAt(field.Pos(), "skipping embedded field in `%s`", c.TypeName)
+		return nil
+	}
+	// The embedded struct's own fields carry any directives, so there's nothing for one on
+	// the embedded field itself to apply to.
+	if data := parseComments(field.Doc); len(data.configs) > 0 {
+		return fmt.Errorf("%s: '%s.%s%s': 'clap:%s' isn't valid on embedded fields",
+			pkg.fset.Position(field.Pos()), c.TypeName, path, name, data.configs[0].key)
+	}
+	declPkg, strct := from.structOf(field.Type)
+	if strct == nil {
+		pkg.warnAt(field.Pos(), "skipping embedded field `%s.%s%s`: it's not a struct defined within this module", c.TypeName, path, name)
+		return nil
+	}
+	if declPkg != pkg && !ast.IsExported(name) {
+		pkg.warnAt(field.Pos(), "skipping embedded field `%s.%s%s`: embedded types from other packages must be exported", c.TypeName, path, name)
+		return nil
+	}
+	if slices.Contains(strings.Split(path, "."), name) {
+		return fmt.Errorf("%s: '%s' embeds itself", pkg.fset.Position(field.Pos()), name)
+	}
+	return addFields(pkg, declPkg, c, strct, path+name+".")
+}
+
+// structOf returns the package and the struct type declaration for the given type
+// expression (which appears in this package), or a nil struct if the expression isn't the
+// name of a struct type defined within the module.
+func (pkg *parsedPackage) structOf(expr ast.Expr) (*parsedPackage, *ast.StructType) {
+	ident := typeIdent(expr)
+	if ident == nil {
+		return nil, nil
+	}
+	obj, ok := pkg.info.Uses[ident].(*types.TypeName)
+	if !ok || obj.Pkg() == nil {
+		return nil, nil
+	}
+	declPkg := pkg.module[obj.Pkg().Path()]
+	if declPkg == nil {
+		return nil, nil
+	}
+	return declPkg, findStruct(declPkg, ident.Name)
+}
+
+// typeIdent returns the identifier of a type name such as `T` or `pkg.T`, or nil if the
+// expression isn't one.
+func typeIdent(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.SelectorExpr:
+		return t.Sel
+	}
 	return nil
 }
 
@@ -181,30 +590,189 @@ type cfgTypes struct {
 	args bool
 }
 
+// scanConfigTypes reports whether any of the given directives are only valid on options
+// or only valid on arguments.
 func scanConfigTypes(cfgs []clapConfig) cfgTypes {
 	var ct cfgTypes
 	for i := range cfgs {
-		k := cfgs[i].key
-		if strings.HasPrefix(k, "opt") {
+		switch directives[cfgs[i].key].place {
+		case onOption:
 			ct.opts = true
-		}
-		if strings.HasPrefix(k, "arg") {
+		case onArgument:
 			ct.args = true
 		}
 	}
 	return ct
 }
 
+// completeFuncType is the type of the functions named by 'clap:complete_func' directives.
+var completeFuncType = types.NewSignatureType(nil, nil, nil,
+	types.NewTuple(types.NewParam(token.NoPos, nil, "prefix", types.Typ[types.String])),
+	types.NewTuple(types.NewParam(token.NoPos, nil, "", types.NewSlice(types.Typ[types.String]))),
+	false)
+
+// checkCompleteFunc returns an error if there's a 'clap:complete_func' directive in the
+// given data that doesn't name a function of this package with the signature
+// `func(prefix string) []string`.
+func (pkg *parsedPackage) checkCompleteFunc(data *clapData) error {
+	name, ok := data.getConfig("complete_func")
+	if !ok {
+		return nil
+	}
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("'clap:complete_func' value '%s' is not a Go identifier", name)
+	}
+	if pkg.types == nil {
+		return nil
+	}
+	obj := pkg.types.Scope().Lookup(name)
+	if obj == nil {
+		return fmt.Errorf("'clap:complete_func' function '%s' is not defined", name)
+	}
+	if !types.AssignableTo(obj.Type(), completeFuncType) {
+		return fmt.Errorf("'clap:complete_func' function '%s' must be a func(prefix string) []string", name)
+	}
+	return nil
+}
+
+// checkCompleteFiles returns an error if there's a 'clap:complete_files' directive in the
+// given data for an option or argument that isn't a string or that's already completed
+// some other way.
+func checkCompleteFiles(data *clapData, typ basicType) error {
+	if _, ok := data.getConfig("complete_files"); !ok {
+		return nil
+	}
+	if typ.ElemType() != "string" {
+		return errors.New("'clap:complete_files' is only valid on string options and arguments")
+	}
+	if _, ok := data.getConfig("complete_func"); ok {
+		return errors.New("'clap:complete_files' can't be used with 'clap:complete_func'")
+	}
+	if data.choices() != nil {
+		return errors.New("'clap:complete_files' can't be used with 'clap:choices'")
+	}
+	return nil
+}
+
+// checkChoices returns an error if there's an invalid 'clap:choices' directive in the
+// given data for an option or argument of the given type.
+func checkChoices(data *clapData, typ basicType) error {
+	choices := data.choices()
+	if choices == nil {
+		return nil
+	}
+	if typ.ElemType() != "string" {
+		return errors.New("'clap:choices' is only valid on string options and arguments")
+	}
+	for _, c := range choices {
+		if c == "" {
+			return errors.New("'clap:choices' has an empty choice")
+		}
+	}
+	return nil
+}
+
+// checkBounds returns an error if there are invalid 'clap:min' or 'clap:max' directives
+// in the given data for an option or argument of the given type. Each bound must be a
+// valid value of the (numeric) type, and the min can't be greater than the max.
+func checkBounds(data *clapData, typ basicType) error {
+	minVal, maxVal := data.bounds()
+	if minVal == "" && maxVal == "" {
+		return nil
+	}
+	kind := typ.ElemType()
+	if _, ok := numLimits[kind]; !ok {
+		return errors.New("'clap:min' and 'clap:max' are only valid on numeric options and arguments")
+	}
+	var bounds [2]float64
+	for i, v := range []string{minVal, maxVal} {
+		if v == "" {
+			continue
+		}
+		f, err := parseNumber(kind, v)
+		if err != nil {
+			if ne, ok := err.(*strconv.NumError); ok {
+				err = ne.Err
+			}
+			return fmt.Errorf("invalid bound '%s' for type %s: %w", v, kind, err)
+		}
+		bounds[i] = f
+	}
+	if minVal != "" && maxVal != "" && bounds[0] > bounds[1] {
+		return fmt.Errorf("min '%s' is greater than max '%s'", minVal, maxVal)
+	}
+	return nil
+}
+
+// parseNumber parses the given string as a value of the given numeric type and returns it
+// as a float64 (which is only meant for comparisons).
+func parseNumber(kind basicType, s string) (float64, error) {
+	switch kind {
+	case "byte":
+		kind = "uint8"
+	case "rune":
+		kind = "int32"
+	}
+	k := string(kind)
+	bits, _ := strconv.Atoi(strings.TrimLeft(k, "intuflo"))
+	switch {
+	case strings.HasPrefix(k, "float"):
+		return strconv.ParseFloat(s, bits)
+	case strings.HasPrefix(k, "uint"):
+		u, err := strconv.ParseUint(s, 0, bits)
+		return float64(u), err
+	default:
+		i, err := strconv.ParseInt(s, 0, bits)
+		return float64(i), err
+	}
+}
+
 func basicTypeFromName(name string) basicType {
 	switch name {
 	case "bool", "string", "byte", "rune", "float32", "float64",
 		"int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64":
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"time.Duration", "time.Time":
 		return basicType(name)
 	}
 	return ""
 }
 
+// supportedType returns the name of the given type if it's one of the basic types or one of
+// the `time` package's types that are directly supported. Otherwise, it returns an empty
+// string. The `time` types are identified by their package path rather than by how they're
+// written in the source, so renamed imports work and other packages named "time" don't.
+func supportedType(t types.Type) basicType {
+	switch t := t.(type) {
+	case *types.Basic:
+		return basicTypeFromName(t.Name())
+	case *types.Named:
+		if pkg := t.Obj().Pkg(); pkg != nil && pkg.Path() == "time" {
+			return basicTypeFromName("time." + t.Obj().Name())
+		}
+	}
+	return ""
+}
+
+// typeDecls returns the package level declarations of types with the given name.
+func (pkg *parsedPackage) typeDecls(name string) []*ast.TypeSpec {
+	var specs []*ast.TypeSpec
+	for _, f := range pkg.files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.Name == name {
+					specs = append(specs, ts)
+				}
+			}
+		}
+	}
+	return specs
+}
+
 func findStruct(pkg *parsedPackage, name string) *ast.StructType {
 	var strct *ast.StructType
 	for _, f := range pkg.files {
@@ -213,13 +781,13 @@ func findStruct(pkg *parsedPackage, name string) *ast.StructType {
 			case *ast.GenDecl:
 				for i := range n.Specs {
 					if s, ok := n.Specs[i].(*ast.TypeSpec); ok && s.Name.Name == name {
-						strct = s.Type.(*ast.StructType)
+						strct, _ = s.Type.(*ast.StructType)
 						return false
 					}
 				}
 			case *ast.TypeSpec:
 				if n.Name.Name == name && n.Doc != nil {
-					strct = n.Type.(*ast.StructType)
+					strct, _ = n.Type.(*ast.StructType)
 					return false
 				}
 			}
@@ -261,16 +829,104 @@ func getCmdClapData(pkg *parsedPackage, typ string) clapData {
 	return parseComments(commentGrp)
 }
 
-func (c *command) addOption(data clapData, fieldName string, typ basicType) error {
-	name, ok := data.getConfig("opt")
+// inheritGlobalOpts sets the given global options (from this command's ancestors) as this
+// command's inherited options and then passes them, along with any of this command's own
+// global options, down to each subcommand. Along the way, it makes sure that no two of a
+// command's options share a name.
+func (c *command) inheritGlobalOpts(inherited []option) error {
+	for i := range c.Opts {
+		o := &c.Opts[i]
+		if o.FieldName == "" {
+			continue // The help option.
+		}
+		if o.Name == "help" || o.sharesNameWith(&helpOption) {
+			return fmt.Errorf("%s: option '%s.%s' conflicts with the help option", o.pos, c.TypeName, o.FieldName)
+		}
+		for j := range c.Opts[:i] {
+			if o.sharesNameWith(&c.Opts[j]) {
+				return fmt.Errorf("%s: option '%s.%s' has the same name as '%s.%s' (%s)", o.pos, c.TypeName, o.FieldName, c.TypeName, c.Opts[j].FieldName, c.Opts[j].pos)
+			}
+		}
+		for j := range inherited {
+			if o.sharesNameWith(&inherited[j]) {
+				return fmt.Errorf("%s: option '%s.%s' conflicts with global option '%s' (%s)", o.pos, c.TypeName, o.FieldName, inherited[j].Name, inherited[j].pos)
+			}
+		}
+	}
+	c.InheritedOpts = inherited
+
+	globals := append([]option{}, inherited...)
+	for i := range c.Opts {
+		if c.Opts[i].IsGlobal() {
+			globals = append(globals, c.Opts[i])
+		}
+	}
+	for i := range c.Subcmds {
+		if err := c.Subcmds[i].inheritGlobalOpts(globals); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sharesNameWith reports whether the two options can be given by the same name on the
+// command line. A single character long name is given with one dash, just like a short
+// name.
+func (o *option) sharesNameWith(other *option) bool {
+	names := func(o *option) []string {
+		if o.Short != "" {
+			return []string{o.Name, o.Short}
+		}
+		return []string{o.Name}
+	}
+	otherNames := names(other)
+	for _, n := range names(o) {
+		if slices.Contains(otherNames, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *command) addOption(data clapData, fieldName string, typ basicType, pos token.Position) error {
+	names, ok := data.getConfig("opt")
 	if !ok {
 		return errors.New("adding option without a 'clap:opt' directive")
 	}
+	// The option can have a second, short name (as in `clap:opt verbose,v`).
+	name, short, _ := strings.Cut(names, ",")
+	name, short = strings.TrimSpace(name), strings.TrimSpace(short)
+	if name == "" {
+		return errors.New("'clap:opt' directive is missing the option name")
+	}
+	if strings.Contains(short, ",") {
+		return fmt.Errorf("option '%s' has more than two names", name)
+	}
+	if len(short) > 1 {
+		return fmt.Errorf("option '%s' has a short name '%s' that's not a single character", name, short)
+	}
+	if _, ok := data.getConfig("opt_required"); ok && typ.IsBool() {
+		return errors.New("boolean options cannot be required")
+	}
+	if _, ok := data.getConfig("opt_sep"); ok && !typ.IsSlice() {
+		return errors.New("'clap:opt_sep' is only valid on slice options")
+	}
+	if _, ok := data.getConfig("opt_time_layout"); ok && typ.ElemType() != "time.Time" {
+		return errors.New("'clap:opt_time_layout' is only valid on time.Time options")
+	}
+	if err := checkChoices(&data, typ); err != nil {
+		return err
+	}
+	if err := checkBounds(&data, typ); err != nil {
+		return err
+	}
 	c.Opts = append(c.Opts, option{
 		FieldType: typ,
 		FieldName: fieldName,
 		Name:      name,
+		Short:     short,
 		data:      data,
+		pos:       pos,
 	})
 	return nil
 }
@@ -0,0 +1,157 @@
+package main
+
+import "testing"
+
+func TestCheckChoices(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		typ     basicType
+		choices string
+		wantErr string
+	}{
+		{name: "none", typ: "int"},
+		{name: "string", typ: "string", choices: "json, yaml"},
+		{name: "string slice", typ: "[]string", choices: "a,b,c"},
+		{
+			name:    "not a string",
+			typ:     "int",
+			choices: "1,2",
+			wantErr: "'clap:choices' is only valid on string options and arguments",
+		},
+		{
+			name:    "empty choice",
+			typ:     "string",
+			choices: "a, ,b",
+			wantErr: "'clap:choices' has an empty choice",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var data clapData
+			if tc.choices != "" {
+				data.configs = []clapConfig{{"choices", tc.choices}}
+			}
+			checkErr(t, checkChoices(&data, tc.typ), tc.wantErr)
+		})
+	}
+}
+
+func TestCheckBounds(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		typ     basicType
+		minVal  string
+		maxVal  string
+		wantErr string
+	}{
+		{name: "none", typ: "string"},
+		{name: "both", typ: "int", minVal: "-5", maxVal: "5"},
+		{name: "only min", typ: "[]uint16", minVal: "1"},
+		{name: "only max", typ: "float32", maxVal: "0.5"},
+		{name: "equal", typ: "int8", minVal: "3", maxVal: "3"},
+		{name: "hex", typ: "byte", minVal: "0x10", maxVal: "0xff"},
+		{
+			name:    "not numeric",
+			typ:     "string",
+			minVal:  "1",
+			wantErr: "'clap:min' and 'clap:max' are only valid on numeric options and arguments",
+		},
+		{
+			name:    "not a number",
+			typ:     "int",
+			maxVal:  "ten",
+			wantErr: "invalid bound 'ten' for type int: invalid syntax",
+		},
+		{
+			name:    "out of range",
+			typ:     "int8",
+			maxVal:  "128",
+			wantErr: "invalid bound '128' for type int8: value out of range",
+		},
+		{
+			name:    "negative unsigned",
+			typ:     "uint",
+			minVal:  "-1",
+			wantErr: "invalid bound '-1' for type uint: invalid syntax",
+		},
+		{
+			name:    "min over max",
+			typ:     "float64",
+			minVal:  "2.5",
+			maxVal:  "1",
+			wantErr: "min '2.5' is greater than max '1'",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var data clapData
+			if tc.minVal != "" {
+				data.configs = append(data.configs, clapConfig{"min", tc.minVal})
+			}
+			if tc.maxVal != "" {
+				data.configs = append(data.configs, clapConfig{"max", tc.maxVal})
+			}
+			checkErr(t, checkBounds(&data, tc.typ), tc.wantErr)
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	for _, tc := range []struct {
+		kind    basicType
+		s       string
+		want    float64
+		wantErr bool
+	}{
+		{kind: "int", s: "-42", want: -42},
+		{kind: "int64", s: "9007199254740992", want: 1 << 53},
+		{kind: "uint8", s: "255", want: 255},
+		{kind: "uint8", s: "256", wantErr: true},
+		{kind: "byte", s: "256", wantErr: true},
+		{kind: "rune", s: "0x10FFFF", want: 0x10FFFF},
+		{kind: "rune", s: "2147483648", wantErr: true},
+		{kind: "uint", s: "0o17", want: 15},
+		{kind: "float32", s: "1.5", want: 1.5},
+		{kind: "float64", s: "1e3", want: 1000},
+		{kind: "int", s: "1.5", wantErr: true},
+	} {
+		got, err := parseNumber(tc.kind, tc.s)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("parseNumber(%s, %q) = %v, want an error", tc.kind, tc.s, got)
+		case !tc.wantErr && err != nil:
+			t.Errorf("parseNumber(%s, %q): unexpected error: %v", tc.kind, tc.s, err)
+		case !tc.wantErr && got != tc.want:
+			t.Errorf("parseNumber(%s, %q) = %v, want %v", tc.kind, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestOptionSharesNameWith(t *testing.T) {
+	for _, tc := range []struct {
+		a, b option
+		want bool
+	}{
+		{a: option{Name: "verbose", Short: "v"}, b: option{Name: "version"}},
+		{a: option{Name: "verbose", Short: "v"}, b: option{Name: "verbose"}, want: true},
+		{a: option{Name: "verbose", Short: "v"}, b: option{Name: "value", Short: "v"}, want: true},
+		{a: option{Name: "verbose", Short: "v"}, b: option{Name: "v"}, want: true},
+		{a: option{Name: "x"}, b: option{Name: "extra", Short: "x"}, want: true},
+		{a: option{Name: "h"}, b: helpOption, want: true},
+		{a: option{Name: "home", Short: "H"}, b: helpOption},
+	} {
+		if got := tc.a.sharesNameWith(&tc.b); got != tc.want {
+			t.Errorf("%q/%q shares a name with %q/%q = %v, want %v", tc.a.Name, tc.a.Short, tc.b.Name, tc.b.Short, got, tc.want)
+		}
+	}
+}
+
+// checkErr reports a test failure unless the given error has the wanted message (or is nil
+// if the wanted message is empty).
+func checkErr(t *testing.T, err error, want string) {
+	t.Helper()
+	switch {
+	case want == "" && err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case want != "" && (err == nil || err.Error() != want):
+		t.Errorf("got error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import "time"
+
+// A command that exercises the generated parser.
+type mycli struct {
+	// Be loud.
+	//
+	// clap:opt verbose,v
+	// clap:opt_global
+	verbose bool
+	// How much to log.
+	//
+	// clap:opt level,l
+	// clap:env PARSER_LEVEL
+	// clap:min 0
+	// clap:max 3
+	level int
+	// The output format.
+	//
+	// clap:opt format
+	// clap:choices text,json
+	format string
+	// Run things.
+	run *run
+	// List things.
+	list *list
+}
+
+// Run things.
+type run struct {
+	// How many times to run.
+	//
+	// clap:opt count,n
+	// clap:opt_required
+	count uint
+	// Tags to apply.
+	//
+	// clap:opt tag,t
+	// clap:opt_sep ,
+	tags []string
+	// How long to wait between runs.
+	//
+	// clap:opt wait
+	wait time.Duration
+	// The things to run.
+	//
+	// clap:arg_required
+	things []string
+}
+
+// List things.
+//
+// clap:cmd_aliases ls
+type list struct {
+	// The port to list things from.
+	//
+	// clap:arg_required
+	port uint16
+	// Only list things that contain this.
+	filter string
+}
+
+func main() {}
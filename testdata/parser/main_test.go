@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// long returns the command line form of a long option name for the style that the parser
+// was generated with (gnuStyle is in a file written alongside the generated code).
+func long(name string) string {
+	if gnuStyle {
+		return "--" + name
+	}
+	return "-" + name
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		style string // Only run for this style if it's set.
+		env   string // The value of PARSER_LEVEL (if any).
+		args  []string
+		want  mycli
+	}{
+		{
+			name: "subcommand",
+			args: []string{"list", "8080"},
+			want: mycli{list: &list{port: 8080}},
+		},
+		{
+			name: "alias and optional argument",
+			args: []string{"ls", "80", "abc"},
+			want: mycli{list: &list{port: 80, filter: "abc"}},
+		},
+		{
+			name: "required option and variadic argument",
+			args: []string{"run", "-n", "2", "a", "b"},
+			want: mycli{run: &run{count: 2, things: []string{"a", "b"}}},
+		},
+		{
+			name: "options on both commands",
+			args: []string{"-l", "1", long("format"), "json", "run", long("count"), "3", long("wait"), "1m", "a"},
+			want: mycli{level: 1, format: "json", run: &run{count: 3, wait: time.Minute, things: []string{"a"}}},
+		},
+		{
+			name: "global option before the subcommand",
+			args: []string{long("verbose"), "list", "1"},
+			want: mycli{verbose: true, list: &list{port: 1}},
+		},
+		{
+			name: "global option after the subcommand",
+			args: []string{"run", "-v", "-n", "1", "a"},
+			want: mycli{verbose: true, run: &run{count: 1, things: []string{"a"}}},
+		},
+		{
+			name: "repeated and separated slice option",
+			args: []string{"run", "-t", "x,y", "-n", "1", "-t", "z", "a"},
+			want: mycli{run: &run{count: 1, tags: []string{"x", "y", "z"}, things: []string{"a"}}},
+		},
+		{
+			name: "double dash",
+			args: []string{"run", "-n", "1", "--", "-v", "b"},
+			want: mycli{run: &run{count: 1, things: []string{"-v", "b"}}},
+		},
+		{
+			name: "env var",
+			env:  "2",
+			args: []string{"list", "1"},
+			want: mycli{level: 2, list: &list{port: 1}},
+		},
+		{
+			name: "command line over env var",
+			env:  "2",
+			args: []string{"-l", "3", "list", "1"},
+			want: mycli{level: 3, list: &list{port: 1}},
+		},
+		{
+			name:  "go style equals",
+			style: "go",
+			args:  []string{"-level=2", "list", "1"},
+			want:  mycli{level: 2, list: &list{port: 1}},
+		},
+		{
+			name:  "go style double dash option",
+			style: "go",
+			args:  []string{"--verbose", "list", "1"},
+			want:  mycli{verbose: true, list: &list{port: 1}},
+		},
+		{
+			name:  "go style options end at the first argument",
+			style: "go",
+			args:  []string{"run", "-n", "1", "a", "-v"},
+			want:  mycli{run: &run{count: 1, things: []string{"a", "-v"}}},
+		},
+		{
+			name:  "gnu style options after arguments",
+			style: "gnu",
+			args:  []string{"run", "a", "-n", "1", "b", "-v"},
+			want:  mycli{verbose: true, run: &run{count: 1, things: []string{"a", "b"}}},
+		},
+		{
+			name:  "gnu style equals",
+			style: "gnu",
+			args:  []string{"--level=2", "list", "1"},
+			want:  mycli{level: 2, list: &list{port: 1}},
+		},
+		{
+			name:  "gnu style bundled short options",
+			style: "gnu",
+			args:  []string{"run", "-vn2", "a"},
+			want:  mycli{verbose: true, run: &run{count: 2, things: []string{"a"}}},
+		},
+		{
+			name:  "gnu style attached short value",
+			style: "gnu",
+			args:  []string{"-l1", "list", "1"},
+			want:  mycli{level: 1, list: &list{port: 1}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.style != "" && (tc.style == "gnu") != gnuStyle {
+				t.Skip("not for this style")
+			}
+			if tc.env != "" {
+				t.Setenv("PARSER_LEVEL", tc.env)
+			}
+			var got mycli
+			if err := got.ParseArgs(tc.args); err != nil {
+				t.Fatalf("%q: unexpected error: %v", tc.args, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%q: got %+v, want %+v", tc.args, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		style string // Only run for this style if it's set.
+		env   string // The value of PARSER_LEVEL (if any).
+		args  []string
+		want  ClapErrorKind
+	}{
+		{name: "help", args: []string{"-h"}, want: ClapKindHelp},
+		{name: "long help", args: []string{"run", long("help")}, want: ClapKindHelp},
+		{name: "unknown option", args: []string{long("bogus"), "list", "1"}, want: ClapKindUnknownOption},
+		{name: "subcommand option on root", args: []string{"-n", "1", "run", "a"}, want: ClapKindUnknownOption},
+		{name: "missing option value", args: []string{"run", "-n"}, want: ClapKindMissingOptionValue},
+		{name: "invalid option value", args: []string{"run", "-n", "two", "a"}, want: ClapKindInvalidOptionValue},
+		{name: "out of bounds", args: []string{"-l", "4", "list", "1"}, want: ClapKindInvalidOptionValue},
+		{name: "not a choice", args: []string{long("format"), "xml", "list", "1"}, want: ClapKindInvalidOptionValue},
+		{name: "missing option", args: []string{"run", "a"}, want: ClapKindMissingOption},
+		{name: "invalid env var", env: "high", args: []string{"list", "1"}, want: ClapKindInvalidEnvVar},
+		{name: "missing argument", args: []string{"list"}, want: ClapKindMissingArg},
+		{name: "missing variadic argument", args: []string{"run", "-n", "1"}, want: ClapKindMissingArg},
+		{name: "invalid argument", args: []string{"list", "port"}, want: ClapKindInvalidArgValue},
+		{name: "argument out of range", args: []string{"list", "70000"}, want: ClapKindInvalidArgValue},
+		{name: "unexpected argument", args: []string{"list", "1", "abc", "x"}, want: ClapKindUnexpectedArg},
+		{name: "missing subcommand", args: []string{"-v"}, want: ClapKindMissingSubcmd},
+		{name: "unknown subcommand", args: []string{"walk"}, want: ClapKindUnknownSubcmd},
+		{name: "gnu style single dash long", style: "gnu", args: []string{"-verbose", "list", "1"}, want: ClapKindUnknownOption},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.style != "" && (tc.style == "gnu") != gnuStyle {
+				t.Skip("not for this style")
+			}
+			if tc.env != "" {
+				t.Setenv("PARSER_LEVEL", tc.env)
+			}
+			var c mycli
+			err := c.ParseArgs(tc.args)
+			var ce *ClapError
+			if !errors.As(err, &ce) {
+				t.Fatalf("%q: got error %v, want a *ClapError", tc.args, err)
+			}
+			if ce.Kind != tc.want {
+				t.Errorf("%q: got kind %d (%v), want %d", tc.args, ce.Kind, err, tc.want)
+			}
+		})
+	}
+}